@@ -0,0 +1,62 @@
+package main
+
+/*************************************************************************
+ * CommandLib
+ *
+ * Every command the player can give, used for keymap files and the help
+ * screen.  CmdNop and CmdClick are left out since they have no key.
+ */
+type CommandTemplate struct {
+	name  string // as used in keymap files
+	desc  string
+	debug bool // for testing, may be left without a key
+}
+
+var CommandLib = map[GameCommand]CommandTemplate{
+	CmdDebug1:       {"debug1", "toggle debug mode, show map", true},
+	CmdDebug2:       {"debug2", "debug level generation", true},
+	CmdDebug3:       {"debug3", "toggle Dijkstra map overlay", true},
+	CmdDebug4:       {"debug4", "toggle pathfinding overlay", true},
+	CmdDebug5:       {"debug5", "cycle pathfinding target", true},
+	CmdQuit:         {"quit", "quit the game", false},
+	CmdWait:         {"wait", "rest for a turn", false},
+	CmdNorth:        {"north", "move north", false},
+	CmdNorthEast:    {"northeast", "move northeast", false},
+	CmdEast:         {"east", "move east", false},
+	CmdSouthEast:    {"southeast", "move southeast", false},
+	CmdSouth:        {"south", "move south", false},
+	CmdSouthWest:    {"southwest", "move southwest", false},
+	CmdWest:         {"west", "move west", false},
+	CmdNorthWest:    {"northwest", "move northwest", false},
+	CmdUp:           {"up", "climb up the stairs", false},
+	CmdDown:         {"down", "go down the stairs", false},
+	CmdConsume:      {"consume", "eat or drink something", false},
+	CmdEquip:        {"equip", "wield, wear or remove", false},
+	CmdThrow:        {"throw", "throw an item", false},
+	CmdDrop:         {"drop", "drop an item", false},
+	CmdPickup:       {"pickup", "pick up an item", false},
+	CmdAutoPickup:   {"autopickup", "toggle auto-pickup", false},
+	CmdCall:         {"call", "name an item type", false},
+	CmdDiscoveries:  {"discoveries", "list known item types", false},
+	CmdSearch:       {"search", "search for secrets", false},
+	CmdRun:          {"run", "run in a direction", false},
+	CmdCount:        {"count", "repeat the next command", false},
+	CmdRunNorth:     {"run-north", "run north", false},
+	CmdRunNorthEast: {"run-northeast", "run northeast", false},
+	CmdRunEast:      {"run-east", "run east", false},
+	CmdRunSouthEast: {"run-southeast", "run southeast", false},
+	CmdRunSouth:     {"run-south", "run south", false},
+	CmdRunSouthWest: {"run-southwest", "run southwest", false},
+	CmdRunWest:      {"run-west", "run west", false},
+	CmdRunNorthWest: {"run-northwest", "run northwest", false},
+	CmdTravel:       {"travel", "travel to a location", false},
+	CmdExplore:      {"explore", "explore automatically", false},
+	CmdLook:         {"look", "look at something", false},
+	CmdHelp:         {"help", "show this help", false},
+	CmdCharacter:    {"character", "show character sheet", false},
+	CmdMonochrome:   {"monochrome", "toggle colours", false},
+	CmdTick:         {"redraw", "redraw the screen", false},
+	CmdGenerate:     {"generate", "generate a test level", true},
+	CmdMessages:     {"messages", "show message history", false},
+	CmdInventory:    {"inventory", "show inventory", false},
+}
@@ -1,19 +1,29 @@
 package main
 
-import (
-	"fmt"
-	"math/rand"
-)
+import "fmt"
 
 // === FOOD ==============================================================
 
 type Food struct {
 	name string
 	amt  int
+	qty  int
 }
 
 func newFood(name string) *Food {
-	return &Food{name, NutritionTime}
+	t, ok := FoodLib[name]
+	if !ok {
+		panic("No food with the name " + name)
+	}
+	return &Food{name, t.nutrition, 1}
+}
+
+// 90% of the food found in the dungeon are rations, the rest slime molds
+func randFood() *Food {
+	if rng.Intn(100) < 10 {
+		return newFood("slime mold")
+	}
+	return newFood("ration")
 }
 
 func (f *Food) Rune() rune {
@@ -25,11 +35,14 @@ func (f *Food) InvString() string {
 }
 
 func (f *Food) GndString() string {
-	return fmt.Sprintf("a %s", f.name)
+	if f.qty > 1 {
+		return fmt.Sprintf("%d %ss", f.qty, f.name)
+	}
+	return fmt.Sprintf("%s %s", article(f.name), f.name)
 }
 
 func (f *Food) Worth() int {
-	return 2
+	return 2 * f.qty
 }
 
 func (f *Food) String() string {
@@ -40,16 +53,47 @@ func (f *Food) Identify() {
 	return
 }
 
+func (f *Food) Count() int {
+	return f.qty
+}
+
+func (f *Food) AdjustCount(amt int) {
+	f.qty += amt
+}
+
+func (f *Food) StacksWith(item Item) bool {
+	f2, ok := item.(*Food)
+	return ok && f.name == f2.name
+}
+
+func (f *Food) Split(qty int) Item {
+	f2 := *f
+	f2.qty = qty
+	f.qty -= qty
+	return &f2
+}
+
 func (f *Food) Consume(gs *GameState) bool {
-	gs.messages.Add("You eat %v.", f.InvString())
+	gs.messages.Add(MsgItem, "%s", FoodLib[f.name].message)
 	gs.player.AdjustFoodCount(f.amt)
 	return true
 }
 
+type FoodTemplate struct {
+	nutrition int
+	message   string
+}
+
+var FoodLib = map[string]FoodTemplate{
+	"ration":     {NutritionTime, "Yum, that tasted good."},
+	"slime mold": {NutritionTime / 2, "My, that was a yummy slime mold."},
+}
+
 // === POTIONS ==========================================================
 
 type Potion struct {
-	id int
+	id  int
+	qty int
 }
 
 func newPotion(name string) *Potion {
@@ -67,12 +111,13 @@ func newPotion(name string) *Potion {
 	}
 
 	return &Potion{
-		id: idx,
+		id:  idx,
+		qty: 1,
 	}
 }
 
 func randPotion() *Potion {
-	roll := rand.Intn(100) + 1 //1-100
+	roll := rng.Intn(100) + 1 //1-100
 	name := ""
 	for _, t := range PotionLib {
 		//debug.Add("rand potion: (%d) chance=%d", roll, t.chance)
@@ -95,16 +140,47 @@ func (p *Potion) InvString() string {
 func (p *Potion) GndString() string {
 	templ := PotionLib[p.id]
 	color := PotionColors[templ.color]
+	called := ""
+	if templ.called != "" {
+		called = " called " + templ.called
+	}
+	if p.qty > 1 {
+		if templ.discovered {
+			return fmt.Sprintf("%d potions of %s [%s]", p.qty, templ.name, color)
+		} else {
+			return fmt.Sprintf("%d %s potions%s", p.qty, color, called)
+		}
+	}
 	if templ.discovered {
 		return fmt.Sprintf("a potion of %s [%s]", templ.name, color)
 	} else {
-		return fmt.Sprintf("a %s potion", color)
+		return fmt.Sprintf("a %s potion%s", color, called)
 	}
 }
 
 func (p *Potion) Worth() int {
 	templ := PotionLib[p.id]
-	return templ.worth
+	return templ.worth * p.qty
+}
+
+func (p *Potion) Count() int {
+	return p.qty
+}
+
+func (p *Potion) AdjustCount(amt int) {
+	p.qty += amt
+}
+
+func (p *Potion) StacksWith(item Item) bool {
+	p2, ok := item.(*Potion)
+	return ok && p.id == p2.id
+}
+
+func (p *Potion) Split(qty int) Item {
+	p2 := *p
+	p2.qty = qty
+	p.qty -= qty
+	return &p2
 }
 
 func (p Potion) String() string {
@@ -114,7 +190,7 @@ func (p Potion) String() string {
 func (p *Potion) Consume(gs *GameState) bool {
 	templ := PotionLib[p.id]
 	doEffect(templ.effect, gs)
-	gs.messages.Add(templ.message)
+	gs.messages.Add(MsgStatus, "%s", templ.message)
 	p.Identify()
 	return true
 }
@@ -126,6 +202,11 @@ func (p *Potion) Identify() {
 	PotionLib[p.id].discovered = true
 }
 
+// Attach a name to this kind of potion, or clear it with an empty string
+func (p *Potion) Call(name string) {
+	PotionLib[p.id].called = name
+}
+
 type PotionTemplate struct {
 	pct        int // probability of this potion being randomly generated
 	cumPct     int // cumulative probability
@@ -135,23 +216,24 @@ type PotionTemplate struct {
 	color      int
 	discovered bool
 	message    string
+	called     string // name given by the player while unidentified
 }
 
 var PotionLib = []PotionTemplate{
-	{15, 15, "healing", E_Healing, 130, 0, false, "You begin to feel better."},
-	{15, 30, "strength", E_Strength, 150, 0, false, "You feel stronger, what bulging muscles!"},
-	{14, 44, "restore strength", E_Restore, 120, 0, false, "Hey, this tastes great, it make you feel warm all over."},
-	{10, 54, "paralysis", E_Paralyze, 50, 0, false, "You feel your body seizing up, you can't move!"},
-	{8, 62, "confusion", E_Confusion, 50, 0, false, "Wait, what's going on here. Huh? What? Who?"},
-	{8, 70, "poison", E_Poison, 50, 0, false, "You feel very sick now."},
-	{6, 76, "monster detection", E_DetMonsters, 120, 0, false, "You feel like you are not alone."},
-	{6, 82, "detect magic", E_DetMagic, 105, 0, false, "You sense the presence of magic."},
-	{5, 87, "extra healing", E_ExtraHealing, 180, 0, false, "You begin to feel much better."},
-	{4, 91, "haste", E_Haste, 200, 0, false, "Tastes like coffee, everything seems to slow down."},
-	{4, 95, "blindness", E_Blindness, 50, 0, false, "A cloak of darkness falls around you."},
-	{2, 97, "raise level", E_LevelUp, 220, 0, false, "You feel more experienced."},
-	{2, 99, "truesight", E_Truesight, 170, 0, false, "Tastes like slime-mold juice."},
-	{1, 100, "thirst quenching", E_Nothing, 50, 0, false, "Meh, tastes pretty dull."},
+	{15, 15, "healing", E_Healing, 130, 0, false, "You begin to feel better.", ""},
+	{15, 30, "strength", E_Strength, 150, 0, false, "You feel stronger, what bulging muscles!", ""},
+	{14, 44, "restore strength", E_Restore, 120, 0, false, "Hey, this tastes great, it make you feel warm all over.", ""},
+	{10, 54, "paralysis", E_Paralyze, 50, 0, false, "You feel your body seizing up, you can't move!", ""},
+	{8, 62, "confusion", E_Confusion, 50, 0, false, "Wait, what's going on here. Huh? What? Who?", ""},
+	{8, 70, "poison", E_Poison, 50, 0, false, "You feel very sick now.", ""},
+	{6, 76, "monster detection", E_DetMonsters, 120, 0, false, "You feel like you are not alone.", ""},
+	{6, 82, "detect magic", E_DetMagic, 105, 0, false, "You sense the presence of magic.", ""},
+	{5, 87, "extra healing", E_ExtraHealing, 180, 0, false, "You begin to feel much better.", ""},
+	{4, 91, "haste", E_Haste, 200, 0, false, "Tastes like coffee, everything seems to slow down.", ""},
+	{4, 95, "blindness", E_Blindness, 50, 0, false, "A cloak of darkness falls around you.", ""},
+	{2, 97, "raise level", E_LevelUp, 220, 0, false, "You feel more experienced.", ""},
+	{2, 99, "truesight", E_Truesight, 170, 0, false, "Tastes like slime-mold juice.", ""},
+	{1, 100, "thirst quenching", E_Nothing, 50, 0, false, "Meh, tastes pretty dull.", ""},
 }
 
 var PotionColors = []string{
@@ -186,9 +268,9 @@ func assignPotionColors() {
 	}
 	used := make(map[int]bool)
 	for pid := range PotionLib {
-		cid := rand.Intn(len(PotionColors))
+		cid := rng.Intn(len(PotionColors))
 		for used[cid] {
-			cid = rand.Intn(len(PotionColors))
+			cid = rng.Intn(len(PotionColors))
 		}
 		used[cid] = true
 		PotionLib[pid].color = cid
@@ -196,6 +278,20 @@ func assignPotionColors() {
 	}
 }
 
+// Lists every potion that has been identified or called by the player
+func potionDiscoveries() []string {
+	var lines []string
+	for _, t := range PotionLib {
+		color := PotionColors[t.color]
+		if t.discovered {
+			lines = append(lines, fmt.Sprintf("potion of %s [%s]", t.name, color))
+		} else if t.called != "" {
+			lines = append(lines, fmt.Sprintf("%s potion called %s", color, t.called))
+		}
+	}
+	return lines
+}
+
 // === SCROLLS ===========================================================
 
 // === STICKS ============================================================
@@ -44,8 +44,8 @@ func drawDebugFrame(d *Display, gs *GameState) {
 	}
 
 	row := 8
-	//for k, v := range gs.player.timer {
-	//	d.Debugf(84, row, "timer[%s]: %d", k, v)
+	//for k, v := range gs.player.status.turns {
+	//	d.Debugf(84, row, "status[%s]: %d", StatusLib[k].name, v)
 	//	row++
 	//}
 
@@ -62,8 +62,8 @@ func drawDebugFrame(d *Display, gs *GameState) {
 func debugMapGrid(disp *Display) {
 	disp.DrawHLine(8, 0, 79, "debug")
 	disp.DrawHLine(16, 0, 79, "debug")
-	disp.DrawVLine(26, 1, 24, "debug")
-	disp.DrawVLine(53, 1, 24, "debug")
+	disp.DrawVLine(26, 1, MapMaxY+1, "debug")
+	disp.DrawVLine(53, 1, MapMaxY+1, "debug")
 
 	disp.Debugf(0, 1, "0")
 	disp.Debugf(27, 1, "1")
@@ -3,6 +3,7 @@ package main
 import (
 	"fmt"
 	"log"
+	"slices"
 	"strings"
 
 	"github.com/gdamore/tcell/v2"
@@ -22,6 +23,18 @@ var KeyCmdLookup = map[tcell.Key]GameCommand{
 	tcell.KeyDown:   CmdSouth,
 }
 
+// Holding shift with the arrow keys to run
+var ShiftKeyCmdLookup = map[tcell.Key]GameCommand{
+	tcell.KeyLeft:  CmdRunWest,
+	tcell.KeyRight: CmdRunEast,
+	tcell.KeyUp:    CmdRunNorth,
+	tcell.KeyDown:  CmdRunSouth,
+	tcell.KeyHome:  CmdRunNorthWest,
+	tcell.KeyPgUp:  CmdRunNorthEast,
+	tcell.KeyEnd:   CmdRunSouthWest,
+	tcell.KeyPgDn:  CmdRunSouthEast,
+}
+
 var RuneCmdLookup = map[rune]GameCommand{
 	'G': CmdGenerate,
 	'.': CmdWait,
@@ -42,6 +55,21 @@ var RuneCmdLookup = map[rune]GameCommand{
 	'i': CmdInventory,
 	'c': CmdConsume,
 	'e': CmdEquip,
+	't': CmdThrow,
+	'd': CmdDrop,
+	',': CmdPickup,
+	'o': CmdAutoPickup,
+	'N': CmdCall,
+	'D': CmdDiscoveries,
+	's': CmdSearch,
+	'f': CmdRun,
+	'n': CmdCount,
+	'_': CmdTravel,
+	'x': CmdExplore,
+	';': CmdLook,
+	'?': CmdHelp,
+	'm': CmdMonochrome,
+	'C': CmdCharacter,
 }
 
 var TileRunes = map[TileType]rune{
@@ -59,9 +87,19 @@ var TileRunes = map[TileType]rune{
 	TileStairsDn: '>',
 }
 
+// Width of the side panel shown on wide terminals
+const PanelWidth = 30
+
+// Commands listed in each column of the help screen and the width of the
+// keys beside them
+const HelpRows, HelpKeyWidth = 20, 12
+
 type Display struct {
-	Screen tcell.Screen
-	styles map[string]tcell.Style
+	Screen     *ViewScreen
+	styles     map[string]tcell.Style
+	clicked    Coord // map position of the last mouse click (see CmdClick)
+	countFrom  int   // digit that started a count (see CmdCount)
+	monochrome bool  // draw everything in the default style
 }
 
 // -----------------------------------------------------------------------------
@@ -73,7 +111,17 @@ func (d *Display) Init() {
 	if err := scr.Init(); err != nil {
 		log.Fatalf("%+v", err)
 	}
+	d.initStyles()
+
+	scr.SetStyle(d.styles["default"])
+	scr.SetCursorStyle(tcell.CursorStyleSteadyBlock)
+	scr.EnableMouse()
+	scr.Clear()
+	d.Screen = newViewScreen(scr)
+}
 
+// -----------------------------------------------------------------------------
+func (d *Display) initStyles() {
 	d.styles = make(map[string]tcell.Style)
 	d.styles["default"] = tcell.StyleDefault.Background(tcell.ColorReset).Foreground(tcell.ColorReset)
 	d.styles["debug"] = tcell.StyleDefault.Foreground(tcell.ColorLightSkyBlue)
@@ -87,11 +135,14 @@ func (d *Display) Init() {
 	d.styles["bluegreen"] = tcell.StyleDefault.Foreground(tcell.ColorAquaMarine)
 	d.styles["green"] = tcell.StyleDefault.Foreground(tcell.ColorGreen)
 	d.styles["darkgreen"] = tcell.StyleDefault.Foreground(tcell.ColorDarkGreen)
-
-	scr.SetStyle(d.styles["default"])
-	scr.SetCursorStyle(tcell.CursorStyleSteadyBlock)
-	scr.Clear()
-	d.Screen = scr
+	d.styles["highlight"] = tcell.StyleDefault.Reverse(true)
+	d.styles["brown"] = tcell.StyleDefault.Foreground(tcell.ColorOlive)
+	d.styles["gray"] = tcell.StyleDefault.Foreground(tcell.ColorGray)
+	d.styles["white"] = tcell.StyleDefault.Foreground(tcell.ColorWhite)
+	d.styles["magenta"] = tcell.StyleDefault.Foreground(tcell.ColorFuchsia)
+	d.styles["cyan"] = tcell.StyleDefault.Foreground(tcell.ColorTeal)
+	d.styles["player"] = d.styles["default"]
+	d.styles["remembered"] = tcell.StyleDefault.Foreground(tcell.ColorDimGray)
 }
 
 // -----------------------------------------------------------------------------
@@ -117,6 +168,9 @@ func (d *Display) Show() {
 // -----------------------------------------------------------------------------
 func (d *Display) Style(styleName string) tcell.Style {
 	style, ok := d.styles[styleName]
+	if d.monochrome && styleName != "highlight" && styleName != "debug" && styleName != "debug2" {
+		ok = false
+	}
 	if !ok {
 		style = tcell.StyleDefault.Background(tcell.ColorReset).Foreground(tcell.ColorReset)
 	}
@@ -194,22 +248,103 @@ func (d *Display) DrawVLine(col int, from, to int, styleName string) {
 // -----------------------------------------------------------------------------
 func (d *Display) DrawActor(a Actor) {
 	x, y := a.Pos().XY()
-	d.Screen.SetContent(x, y+1, a.Rune(), nil, d.Style("default"))
+	d.Screen.SetContent(x, y+1, a.Rune(), nil, d.Style(a.Color()))
 }
 
 // -----------------------------------------------------------------------------
 func (d *Display) DrawItem(pos Coord, item Item) {
 	x, y := pos.XY()
-	d.Screen.SetContent(x, y+1, item.Rune(), nil, d.Style("default"))
+	d.Screen.SetContent(x, y+1, item.Rune(), nil, d.Style(ItemClassStyles[item.Rune()]))
 }
 
 // -----------------------------------------------------------------------------
 func (d *Display) DrawPlayer(p *Player) {
 	x, y := p.Pos().XY()
-	d.Screen.SetContent(x, y+1, '@', nil, d.Style("default"))
+	d.Screen.SetContent(x, y+1, '@', nil, d.Style(p.Color()))
 	d.Screen.ShowCursor(x, y+1)
 }
 
+// -----------------------------------------------------------------------------
+// Draws the side panel to the right of the map if the terminal is wide enough
+// for it, returning false if it isn't.
+func (d *Display) DrawSidePanel(gs *GameState) bool {
+	if !d.Screen.panel {
+		return false
+	}
+	col := MapMaxX + 2
+	p := gs.player
+	line := func(row int, format string, vals ...any) {
+		d.Printf(col, row, "%-*.*s", PanelWidth, PanelWidth, fmt.Sprintf(format, vals...))
+	}
+
+	row := 1
+	line(row, "Depth %d", p.depth)
+	row += 2
+
+	line(row, "Equipment")
+	row++
+	for _, slot := range EquipSlots {
+		if eq := p.equiped[slot]; eq != nil {
+			line(row, "%s) %s", EquipSlotLabels[slot], eq.InvString())
+		} else {
+			line(row, "%s) -", EquipSlotLabels[slot])
+		}
+		row++
+	}
+	row++
+
+	hunger := p.HungerString()
+	if hunger == "" {
+		hunger = "Not hungry"
+	}
+	line(row, "%s", hunger)
+	row += 2
+
+	line(row, "Effects")
+	row++
+	effects := p.status.Active()
+	if len(effects) == 0 {
+		line(row, "  none")
+		row++
+	}
+	for _, t := range effects {
+		line(row, "  %-20s %3d", StatusLib[t].name, p.status.Turns(t))
+		row++
+	}
+	row++
+
+	line(row, "Monsters")
+	row++
+	seen := gs.SeenMonsters()
+	if len(seen) == 0 {
+		line(row, "  none")
+	}
+	for _, m := range seen {
+		if row >= StatusRow {
+			break
+		}
+		line(row, "  %c %-14.14s", m.Symbol, m.Name)
+		d.DrawText(col+2, row, m.Color(), string(m.Symbol))
+		d.drawHealthBar(col+19, row, m.HP, m.maxHP)
+		row++
+	}
+	return true
+}
+
+// Draws a bar 10 characters wide showing how much health is left
+func (d *Display) drawHealthBar(x, y int, hp, maxHP int) {
+	filled := min(max(hp*10/max(maxHP, 1), 1), 10)
+	style := "green"
+	switch {
+	case filled <= 3:
+		style = "red"
+	case filled <= 6:
+		style = "yellow"
+	}
+	d.DrawText(x, y, style, strings.Repeat("#", filled))
+	d.DrawText(x+filled, y, "remembered", strings.Repeat("-", 10-filled))
+}
+
 // -----------------------------------------------------------------------------
 func (d *Display) DrawMap(m *DungeonMap, showAll bool) {
 	for x, col := range m.tiles {
@@ -222,34 +357,188 @@ func (d *Display) DrawMap(m *DungeonMap, showAll bool) {
 
 			if t.visible {
 				// y+1 because first line is the message line
-				d.Screen.SetContent(x, y+1, r, nil, d.Style("default"))
+				d.Screen.SetContent(x, y+1, r, nil, d.Style(TileStyles[t.typ]))
 			} else if t.visited && t.typ != TileFloor {
-				// have the option to use a different style here
-				d.Screen.SetContent(x, y+1, r, nil, d.Style("default"))
+				// remembered but out of sight
+				d.Screen.SetContent(x, y+1, r, nil, d.Style("remembered"))
 			}
 		}
 	}
 }
 
 // -----------------------------------------------------------------------------
+// Shows the unread messages on the top line.  If they don't all fit, waits
+// for space at a --More-- prompt before showing the rest (ESC skips ahead to
+// the last line).
 func (d *Display) DrawMessages(log *MessageLog) {
-	if log.HasUnread() {
-		s := log.LatestAsStr()
-		drawTextWrap(d.Screen, 0, 0, 80, 3, d.Style("default"), s)
-		//d.Print(0, 0, s)
-		log.ClearUnread()
+	if !log.HasUnread() {
+		return
 	}
+	lines := wrapMessages(log.Unread(), 80-len(" --More--"))
+	log.ClearUnread()
+
+	for i, line := range lines {
+		d.Print(0, 0, strings.Repeat(" ", 80))
+		width := d.drawMessageLine(line)
+		if i == len(lines)-1 {
+			break
+		}
+		d.Print(width+1, 0, "--More--")
+		d.Screen.HideCursor()
+		d.Show()
+
+		ch := d.PromptRune()
+		for ch != ' ' && ch != '\r' && ch != -1 {
+			ch = d.PromptRune()
+		}
+		if ch == -1 {
+			d.Print(0, 0, strings.Repeat(" ", 80))
+			d.drawMessageLine(lines[len(lines)-1])
+			break
+		}
+	}
+}
+
+// Draws each message in the colour of its category, returning the width
+func (d *Display) drawMessageLine(line []Message) int {
+	x := 0
+	for _, m := range line {
+		text := m.String()
+		d.DrawText(x, 0, MsgStyles[m.cat], text)
+		x += len(text) + 1
+	}
+	return x - 1
+}
+
+// Groups messages into lines no wider than width.  A message is only split
+// across lines if it is too long to fit on one by itself.
+func wrapMessages(msgs []Message, width int) [][]Message {
+	var lines [][]Message
+	var line []Message
+	lineLen := 0
+	add := func(m Message) {
+		text := m.String()
+		if len(line) > 0 && lineLen+1+len(text) > width {
+			lines = append(lines, line)
+			line, lineLen = nil, 0
+		}
+		if len(line) > 0 {
+			lineLen++
+		}
+		line = append(line, m)
+		lineLen += len(text)
+	}
+
+	for _, m := range msgs {
+		if len(m.String()) <= width {
+			add(m)
+			continue
+		}
+		for _, word := range strings.Fields(m.String()) {
+			add(Message{text: word, cat: m.cat})
+		}
+	}
+	if len(line) > 0 {
+		lines = append(lines, line)
+	}
+	return lines
 }
 
 // -----------------------------------------------------------------------------
-func (d *Display) DrawMessageHistory(log *MessageLog) {
-	d.Clear()
-	for i, m := range log.Last(22) {
-		d.Printf(0, i, "%v", m)
+// Shows the message history along with the turn of each message.  It can be
+// scrolled back with the arrow keys, page up/down or the mouse wheel, searched
+// with / (n to find the next match) and filtered by category with f until
+// space or ESC is pressed.
+func (d *Display) MessageHistoryScreen(log *MessageLog) {
+	height := ScreenHeight - 3
+	offset := 0 // number of lines scrolled back from the latest message
+	search := ""
+	found := -1  // index of the message matching the search
+	filter := -1 // category to show, -1 for all of them
+	status := ""
+
+	for {
+		msgs := log.messages
+		if filter != -1 {
+			msgs = log.Filter(MsgCategory(filter))
+		}
+		maxOffset := max(len(msgs)-height, 0)
+		offset = min(max(offset, 0), maxOffset)
+
+		d.Clear()
+		end := len(msgs) - offset
+		start := max(end-height, 0)
+		for i, m := range msgs[start:end] {
+			d.Printf(0, i, "%5d", m.turn)
+			style := MsgStyles[m.cat]
+			if start+i == found {
+				style = "highlight"
+			}
+			d.DrawText(7, i, style, m.String())
+		}
+		if filter != -1 {
+			status = fmt.Sprintf("Showing %s messages only. %s", MsgCategoryNames[MsgCategory(filter)], status)
+		}
+		if status != "" {
+			d.Print(0, StatusRow-1, status)
+		}
+		d.Printf(0, StatusRow, "Press space to continue (arrows, page up/down, / to search, f to filter)")
+		d.Screen.HideCursor()
+		d.Show()
+		status = ""
+
+		switch ev := d.Screen.PollEvent().(type) {
+		case *tcell.EventKey:
+			switch {
+			case ev.Key() == tcell.KeyEscape || ev.Rune() == ' ':
+				return
+			case ev.Key() == tcell.KeyUp:
+				offset++
+			case ev.Key() == tcell.KeyDown:
+				offset--
+			case ev.Key() == tcell.KeyPgUp:
+				offset += height
+			case ev.Key() == tcell.KeyPgDn:
+				offset -= height
+			case ev.Key() == tcell.KeyHome:
+				offset = maxOffset
+			case ev.Key() == tcell.KeyEnd:
+				offset = 0
+			case ev.Rune() == 'f':
+				// Cycle through the categories and back to all of them
+				filter++
+				if filter >= len(MsgCategoryNames) {
+					filter = -1
+				}
+				offset, found = 0, -1
+			case ev.Rune() == '/' || (ev.Rune() == 'n' && search != ""):
+				before := found
+				if ev.Rune() == '/' {
+					text, ok := d.PromptString("Search for:")
+					if !ok || text == "" {
+						continue
+					}
+					search, before = text, end
+				}
+				if before == -1 {
+					before = end
+				}
+				if found = searchMessages(msgs, search, before); found == -1 {
+					status = fmt.Sprintf("No earlier messages containing %q.", search)
+				} else {
+					// Scroll so the match is the bottom line
+					offset = len(msgs) - found - 1
+				}
+			}
+		case *tcell.EventMouse:
+			switch {
+			case ev.Buttons()&tcell.WheelUp != 0:
+				offset += 3
+			case ev.Buttons()&tcell.WheelDown != 0:
+				offset -= 3
+			}
+		}
 	}
-	d.Printf(0, 24, "Press space to continue...")
-	d.Screen.HideCursor()
-	d.Show()
 }
 
 // -----------------------------------------------------------------------------
@@ -257,44 +546,235 @@ func (d *Display) InventoryScreen(p *Player) {
 	d.Clear()
 	d.Print(0, 0, "You are carrying:")
 	d.ListInventory(p, 0, false)
+	d.Printf(0, 23, "Press space to continue...")
+	d.Screen.HideCursor()
+	d.Show()
+}
 
-	col := 60
-	row := 1
+// -----------------------------------------------------------------------------
+func (d *Display) CharacterScreen(p *Player) {
+	d.Clear()
+	for i, str := range p.StatsStrings() {
+		d.Print(0, i, str)
+	}
 
-	//label := map[string]string{
-	//	"weapon": "W",
-	//	"armor":  "A",
-	//	"left":   "L",
-	//	"right":  "R",
-	//}
-	//order := []string{"weapon", "armor", "left", "right"}
-	//d.Printf(col, 0, "Equipment")
-	//for _, slot := range order {
-	//	if p.equiped[slot] == nil {
-	//		d.Printf(col, row, "%s) -none-", label[slot])
-	//	} else {
-	//		d.Printf(col, row, "%s) %v", label[slot], p.equiped[slot].InvString())
-	//	}
-	//	row++
-	//}
-
-	d.Printf(col, row, "       STATS")
-	row += 2
-	stats := p.StatsStrings()
-	for _, str := range stats {
-		d.Printf(col, row, str)
+	col := 44
+	row := 0
+	d.Print(col, row, "Armor Class")
+	row++
+	for _, str := range p.ArmorStrings() {
+		d.Print(col+2, row, str)
 		row++
 	}
+	row++
 
+	d.Print(col, row, "Equipment")
+	row++
+	for _, slot := range EquipSlots {
+		if eq := p.equiped[slot]; eq != nil {
+			d.Printf(col+2, row, "%s) %.32s", EquipSlotLabels[slot], eq.InvString())
+		} else {
+			d.Printf(col+2, row, "%s) -none-", EquipSlotLabels[slot])
+		}
+		row++
+	}
+	row++
+
+	d.Print(col, row, "Effects")
+	row++
+	effects := p.status.Active()
+	if len(effects) == 0 {
+		d.Print(col+2, row, "none")
+	}
+	for _, t := range effects {
+		d.Printf(col+2, row, "%-20s %3d turns", StatusLib[t].name, p.status.Turns(t))
+		row++
+	}
+
+	d.Printf(0, 23, "Press space to continue...")
+	d.Screen.HideCursor()
+	d.Show()
+}
+
+// -----------------------------------------------------------------------------
+func (d *Display) DiscoveriesScreen() {
+	families := []struct {
+		name  string
+		lines []string
+	}{
+		{"Potions", potionDiscoveries()},
+	}
+
+	d.Clear()
+	d.Print(0, 0, "Discoveries:")
+	row := 2
+	for _, f := range families {
+		d.Print(0, row, f.name)
+		row++
+		if len(f.lines) == 0 {
+			d.Print(2, row, "none")
+			row++
+		}
+		for _, line := range f.lines {
+			d.Print(2, row, line)
+			row++
+		}
+		row++
+	}
 	d.Printf(0, 23, "Press space to continue...")
 	d.Screen.HideCursor()
 	d.Show()
 }
 
+// -----------------------------------------------------------------------------
+// Shows the help pages: every command along with the keys currently bound to
+// it, followed by a legend of the symbols on the map.  Debug commands are only
+// listed in wizard mode.
+func (d *Display) HelpScreen() {
+	cmds := make([]GameCommand, 0, len(CommandLib))
+	for cmd, c := range CommandLib {
+		if !c.debug || wizardMode {
+			cmds = append(cmds, cmd)
+		}
+	}
+	slices.Sort(cmds)
+
+	// As many pages of commands as needed, two columns each
+	var pages []func()
+	for i := 0; i < len(cmds); i += 2 * HelpRows {
+		page := cmds[i:min(i+2*HelpRows, len(cmds))]
+		pages = append(pages, func() { d.drawCommandHelp(page) })
+	}
+	pages = append(pages, d.drawSymbolLegend)
+
+	for i, draw := range pages {
+		d.Clear()
+		draw()
+		if i < len(pages)-1 {
+			d.Print(0, 23, "Press space for more, ESC to exit...")
+		} else {
+			d.Print(0, 23, "Press space to continue...")
+		}
+		d.Screen.HideCursor()
+		d.Show()
+
+		ch := d.PromptRune()
+		for ch != ' ' && ch != -1 {
+			ch = d.PromptRune()
+		}
+		if ch == -1 {
+			return
+		}
+	}
+}
+
+func (d *Display) drawCommandHelp(cmds []GameCommand) {
+	bound := ActiveBindings()
+	d.Print(0, 0, "Commands:")
+	if ex := countExample(bound); ex != "" {
+		d.Printf(12, 0, "(repeat with a count, e.g. %s searches 20 times)", ex)
+	}
+	perCol := min((len(cmds)+1)/2, HelpRows)
+	for i, cmd := range cmds {
+		col, row := (i/perCol)*40, i%perCol+2
+		d.Printf(col, row, "%-*s %s", HelpKeyWidth, keyList(bound[cmd], HelpKeyWidth), CommandLib[cmd].desc)
+	}
+}
+
+// Lists as many of the keys as will fit in the width, ending with "…" if
+// any had to be left out
+func keyList(keys []KeyBinding, width int) string {
+	list := ""
+	for i, k := range keys {
+		str := k.String()
+		if list != "" {
+			str = " " + str
+		}
+		// Unless this is the last key, leave room to mark the ones after it
+		room := width - 2
+		if i == len(keys)-1 {
+			room = width
+		}
+		if len(list+str) > room {
+			switch {
+			case list == "" && len(str) < width:
+				return str + "…"
+			case list == "":
+				return str[:width-1] + "…"
+			default:
+				return list + " …"
+			}
+		}
+		list += str
+	}
+	return list
+}
+
+// Returns an example of searching 20 times with the current keys, e.g. "n20s"
+// or just "20s" when the digits are bound to count
+func countExample(bound map[GameCommand][]KeyBinding) string {
+	if len(bound[CmdCount]) == 0 || len(bound[CmdSearch]) == 0 {
+		return ""
+	}
+	prefix := bound[CmdCount][0].String()
+	for _, k := range bound[CmdCount] {
+		if k.key == tcell.KeyRune && k.ch >= '1' && k.ch <= '9' {
+			prefix = ""
+		}
+	}
+	return prefix + "20" + bound[CmdSearch][0].String()
+}
+
+func (d *Display) drawSymbolLegend() {
+	d.Print(0, 0, "Symbols:")
+
+	row := 2
+	d.Print(0, row, "Terrain")
+	row++
+	seen := make(map[rune]bool)
+	for t := TileType(0); int(t) < len(TileRunes); t++ {
+		r := TileRunes[t]
+		if r == ' ' || seen[r] {
+			continue
+		}
+		seen[r] = true
+		d.DrawText(2, row, TileStyles[t], string(r))
+		d.Printf(5, row, "%s", TileNames[t])
+		row++
+	}
+
+	row++
+	d.Print(0, row, "Items")
+	row++
+	for _, r := range []rune{'*', '%', '!', ')', ']'} {
+		d.DrawText(2, row, ItemClassStyles[r], string(r))
+		d.Printf(5, row, "%s", ItemClassNames[r])
+		row++
+	}
+
+	// Monsters are listed in two columns on the right
+	d.Print(30, 2, "Monsters")
+	d.DrawText(32, 3, "player", "@")
+	d.Print(35, 3, "you")
+	perCol := (len(MonsterLib) + 2) / 2
+	for i, m := range MonsterLib {
+		col, row := 32+((i+1)/perCol)*24, (i+1)%perCol+3
+		d.DrawText(col, row, m.color, string(m.Symbol))
+		d.Print(col+3, row, m.Name)
+	}
+}
+
 // -----------------------------------------------------------------------------
 func (d *Display) PromptInventory(prompt string, p *Player) int {
-	lo := 'a'
-	hi := rune(int(lo) + len(p.inventory) - 1)
+	if len(p.inventory) == 0 {
+		d.Print(0, 0, strings.Repeat(" ", 80))
+		d.Print(0, 0, "You are empty handed.")
+		d.Show()
+		d.PromptRune()
+		return -1
+	}
+	lo := p.Letter(p.inventory[0])
+	hi := p.Letter(p.inventory[len(p.inventory)-1])
 	str := fmt.Sprintf("%s (%c-%c, ? for list, ESC to cancel):", prompt, lo, hi)
 
 	d.Print(0, 0, strings.Repeat(" ", 80))
@@ -302,15 +782,58 @@ func (d *Display) PromptInventory(prompt string, p *Player) int {
 	d.Screen.ShowCursor(len(str), 0)
 	d.Show()
 
-	ch := d.PromptRune()
-	if ch == '?' {
-		d.ListInventory(p, len(str), false)
-		ch = d.PromptRune()
+	// Items can also be clicked on once the list is shown
+	listed := false
+	for {
+		switch ev := d.Screen.PollEvent().(type) {
+		case *tcell.EventKey:
+			if ev.Key() == tcell.KeyEscape {
+				return -1
+			}
+			if ev.Rune() == '?' && !listed {
+				d.ListInventory(p, len(str), false)
+				listed = true
+				continue
+			}
+			return p.InvIndex(ev.Rune())
+
+		case *tcell.EventMouse:
+			if ev.Buttons()&tcell.Button1 == 0 {
+				continue
+			}
+			if !listed {
+				d.ListInventory(p, len(str), false)
+				listed = true
+				continue
+			}
+			if _, y := ev.Position(); y >= 1 && y <= len(p.inventory) {
+				return y - 1
+			}
+		}
 	}
-	if ch >= lo && ch <= hi {
-		return int(ch - 'a')
+}
+
+// -----------------------------------------------------------------------------
+// Waits for space or ESC to leave the inventory screen.  Clicking on an item
+// shows more details about it.
+func (d *Display) BrowseInventory(p *Player) {
+	for {
+		switch ev := d.Screen.PollEvent().(type) {
+		case *tcell.EventKey:
+			if ev.Key() == tcell.KeyEscape || ev.Rune() == ' ' {
+				return
+			}
+		case *tcell.EventMouse:
+			_, y := ev.Position()
+			if ev.Buttons()&tcell.Button1 == 0 || y < 1 || y > len(p.inventory) {
+				continue
+			}
+			item := p.inventory[y-1]
+			d.Print(0, 22, strings.Repeat(" ", 80))
+			d.Printf(0, 22, "%c) %s (worth %d)", p.Letter(item), item.InvString(), item.Worth())
+			d.Show()
+		}
 	}
-	return -1
 }
 
 // -----------------------------------------------------------------------------
@@ -334,7 +857,7 @@ func (d *Display) ListInventory(p *Player, startWidth int, showWorth bool) {
 		if item == p.equiped["armor"] {
 			equip = " (being worn)"
 		}
-		str := fmt.Sprintf("%c) %c %v%s", 'a'+i, item.Rune(), item.InvString(), equip)
+		str := fmt.Sprintf("%c) %c %v%s", p.Letter(item), item.Rune(), item.InvString(), equip)
 
 		if check := len(str); check > width {
 			width = check
@@ -364,19 +887,188 @@ func (d *Display) ListInventory(p *Player, startWidth int, showWorth bool) {
 	d.Show()
 }
 
+// -----------------------------------------------------------------------------
+// Shows the prompt on the message line and waits for a single keypress
+func (d *Display) Prompt(prompt string) rune {
+	d.Print(0, 0, strings.Repeat(" ", 80))
+	d.Print(0, 0, prompt)
+	d.Screen.ShowCursor(len(prompt), 0)
+	d.Show()
+	return d.PromptRune()
+}
+
+// -----------------------------------------------------------------------------
+// Prompts for a line of text on the message line.  Returns false if ESC is
+// pressed to cancel.
+func (d *Display) PromptString(prompt string) (string, bool) {
+	text := []rune{}
+	for {
+		str := fmt.Sprintf("%s %s", prompt, string(text))
+		d.Print(0, 0, strings.Repeat(" ", 80))
+		d.Print(0, 0, str)
+		d.Screen.ShowCursor(len([]rune(str)), 0)
+		d.Show()
+
+		ev, ok := d.Screen.PollEvent().(*tcell.EventKey)
+		if !ok {
+			continue
+		}
+		switch ev.Key() {
+		case tcell.KeyEscape:
+			return "", false
+		case tcell.KeyEnter:
+			return strings.TrimSpace(string(text)), true
+		case tcell.KeyBackspace, tcell.KeyBackspace2:
+			if len(text) > 0 {
+				text = text[:len(text)-1]
+			}
+		case tcell.KeyRune:
+			if len(str) < 79 {
+				text = append(text, ev.Rune())
+			}
+		}
+	}
+}
+
 // -----------------------------------------------------------------------------
 func (d *Display) PromptRune() rune {
-	ev := d.Screen.PollEvent()
+	for {
+		ev := d.Screen.PollEvent()
 
-	switch ev := ev.(type) {
-	case *tcell.EventKey:
-		if ev.Key() == tcell.KeyEscape {
-			return -1
-		} else {
-			return ev.Rune()
+		switch ev := ev.(type) {
+		case *tcell.EventKey:
+			if ev.Key() == tcell.KeyEscape {
+				return -1
+			} else {
+				return ev.Rune()
+			}
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Prompts for one of the movement keys and returns its direction.  Returns
+// false if anything else is pressed (e.g. ESC to cancel).
+func (d *Display) PromptDirection(prompt string) (Coord, bool) {
+	str := fmt.Sprintf("%s (ESC to cancel)", prompt)
+	d.Print(0, 0, strings.Repeat(" ", 80))
+	d.Print(0, 0, str)
+	d.Screen.ShowCursor(len(str), 0)
+	d.Show()
+
+	for {
+		ev := d.Screen.PollEvent()
+		ek, ok := ev.(*tcell.EventKey)
+		if !ok {
+			continue
+		}
+		cmd, _ := lookupKey(ek)
+		dir, ok := CmdDirections[cmd]
+		return dir, ok
+	}
+}
+
+// -----------------------------------------------------------------------------
+// Reads digits for a repeat count followed by the command to repeat.  The
+// count defaults to 1 if no digits are given.
+func (d *Display) PromptCount() (int, GameCommand) {
+	count := d.countFrom
+	d.countFrom = 0
+	for {
+		str := fmt.Sprintf("Count: %d", count)
+		d.Print(0, 0, strings.Repeat(" ", 80))
+		d.Print(0, 0, str)
+		d.Screen.ShowCursor(len(str), 0)
+		d.Show()
+
+		ev, ok := d.Screen.PollEvent().(*tcell.EventKey)
+		if !ok {
+			continue
+		}
+		rn := ev.Rune()
+		switch {
+		case ev.Key() == tcell.KeyRune && rn >= '0' && rn <= '9':
+			if count < 1000 {
+				count = count*10 + int(rn-'0')
+			}
+		case ev.Key() == tcell.KeyBackspace || ev.Key() == tcell.KeyBackspace2:
+			count /= 10
+		case ev.Key() == tcell.KeyEscape:
+			return 0, CmdNop
+		default:
+			cmd, _ := lookupKey(ev)
+			return max(count, 1), cmd
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+// A targeting cursor that lets the player pick a position on the map with the
+// movement keys or the mouse, starting at the given one.  Pressing a symbol
+// (e.g. '>') jumps to the positions returned by the jump function, cycling
+// through them if pressed again.  If describe is given, the message line
+// describes whatever is under the cursor.  Returns false if cancelled.
+func (d *Display) SelectPosition(prompt string, start Coord, jump func(rune) []Coord,
+	describe func(Coord) string) (Coord, bool) {
+
+	str := fmt.Sprintf("%s (move cursor, . to select, ESC to cancel)", prompt)
+	d.Print(0, 0, strings.Repeat(" ", 80))
+	d.Print(0, 0, str)
+
+	pos := start
+	jumpIdx := 0
+	var lastJump rune
+	for {
+		d.Screen.ShowCursor(pos.X, pos.Y+1)
+		d.Show()
+
+		moved := false
+		switch ev := d.Screen.PollEvent().(type) {
+		case *tcell.EventMouse:
+			x, y := ev.Position()
+			click := Coord{x, y - 1}
+			if click.X < 0 || click.X >= MapMaxX || click.Y < 0 || click.Y >= MapMaxY {
+				continue
+			}
+			if ev.Buttons()&tcell.Button1 != 0 {
+				return click, true
+			}
+			moved = click != pos
+			pos = click
+
+		case *tcell.EventKey:
+			switch {
+			case ev.Key() == tcell.KeyEscape:
+				return pos, false
+			case ev.Key() == tcell.KeyEnter || ev.Rune() == '.':
+				return pos, true
+			}
+
+			cmd, _ := lookupKey(ev)
+			if dir, ok := CmdDirections[cmd]; ok {
+				next := pos.Sum(dir)
+				if next.X >= 0 && next.X < MapMaxX && next.Y >= 0 && next.Y < MapMaxY {
+					pos = next
+					moved = true
+				}
+			} else if ev.Key() == tcell.KeyRune && jump != nil {
+				if ev.Rune() != lastJump {
+					lastJump = ev.Rune()
+					jumpIdx = 0
+				}
+				if list := jump(ev.Rune()); len(list) > 0 {
+					pos = list[jumpIdx%len(list)]
+					jumpIdx++
+					moved = true
+				}
+			}
+		}
+
+		if moved && describe != nil {
+			d.Print(0, 0, strings.Repeat(" ", 80))
+			d.Print(0, 0, describe(pos))
 		}
 	}
-	return 0
 }
 
 // -----------------------------------------------------------------------------
@@ -390,9 +1082,11 @@ func (d *Display) WaitForKeypress() {
 
 // -----------------------------------------------------------------------------
 // Handles all events appropriateley (e.g. resizing) but this functions will only
-// return when a key event is received.  Will return 0 if the command is not
-// recognized along with creating a game message.
-func (d *Display) GetCommand(msg *MessageLog) (cmd GameCommand) {
+// return when a key event or mouse click is received.  Will return 0 if the
+// command is not recognized along with creating a game message.  Hovering the
+// mouse over the map describes what is there on the status line.
+func (d *Display) GetCommand(gs *GameState) (cmd GameCommand) {
+	msg := gs.messages
 
 	gotEventKey := false
 	for !gotEventKey {
@@ -402,30 +1096,66 @@ func (d *Display) GetCommand(msg *MessageLog) (cmd GameCommand) {
 
 		switch ev := ev.(type) {
 		case *tcell.EventResize:
-			//d.Screen.Clear()
+			// The view has already been laid out again (see ViewScreen) but
+			// redraw everything in case the side panel now fits or doesn't
 			d.Screen.Sync()
+			return CmdTick
+
+		case *tcell.EventMouse:
+			x, y := ev.Position()
+			pos := Coord{x, y - 1}
+			onMap := pos.X >= 0 && pos.X < MapMaxX && pos.Y >= 0 && pos.Y < MapMaxY
+
+			switch {
+			case ev.Buttons()&tcell.Button1 != 0 && onMap:
+				d.clicked = pos
+				return CmdClick
+			case ev.Buttons() == tcell.ButtonNone:
+				d.Print(0, StatusRow, strings.Repeat(" ", 80))
+				if onMap {
+					d.Print(0, StatusRow, gs.Describe(pos))
+				} else {
+					d.Print(0, StatusRow, gs.player.InfoString())
+				}
+				d.Show()
+			}
 
 		case *tcell.EventKey:
 			gotEventKey = true
-			key := ev.Key()
-			rn := ev.Rune()
-
 			var ok bool
-			switch key {
-			case tcell.KeyRune:
-				if cmd, ok = RuneCmdLookup[rn]; !ok {
-					msg.Add("I don't know that command (%c)", rn)
-				}
-			default:
-				if cmd, ok = KeyCmdLookup[key]; !ok {
-					msg.Add("I don't know that command (%v)", tcell.KeyNames[key])
+			if cmd, ok = lookupKey(ev); !ok {
+				if ev.Key() == tcell.KeyRune {
+					msg.Add(MsgSystem, "I don't know that command (%c)", ev.Rune())
+				} else {
+					msg.Add(MsgSystem, "I don't know that command (%v)", tcell.KeyNames[ev.Key()])
 				}
 			}
+			// A digit bound to count is the first digit of the count
+			if rn := ev.Rune(); cmd == CmdCount && ev.Key() == tcell.KeyRune && rn >= '0' && rn <= '9' {
+				d.countFrom = int(rn - '0')
+			}
 		}
 	}
 	return cmd
 }
 
+// -----------------------------------------------------------------------------
+// Translates a key event into a command
+func lookupKey(ev *tcell.EventKey) (GameCommand, bool) {
+	key := ev.Key()
+	if key == tcell.KeyRune {
+		cmd, ok := RuneCmdLookup[ev.Rune()]
+		return cmd, ok
+	}
+	if ev.Modifiers()&tcell.ModShift != 0 {
+		if cmd, ok := ShiftKeyCmdLookup[key]; ok {
+			return cmd, ok
+		}
+	}
+	cmd, ok := KeyCmdLookup[key]
+	return cmd, ok
+}
+
 // -----------------------------------------------------------------------------
 func (d *Display) TombstoneScreen(gs *GameState) {
 
@@ -465,7 +1195,7 @@ func (d *Display) TombstoneScreen(gs *GameState) {
 		d.Print(col, row, str)
 		row++
 	}
-	name := "Nameless Hero"
+	name := gs.player.Name
 	d.Print(40-(len(name)/2), 24-13, name)
 	killedBy := gs.player.killedBy
 	d.Print(40-(len(killedBy)/2), 24-10, killedBy)
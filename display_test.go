@@ -0,0 +1,30 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestKeyList(t *testing.T) {
+	r := func(ch rune) KeyBinding { return KeyBinding{key: tcell.KeyRune, ch: ch} }
+	shift := func(key tcell.Key) KeyBinding { return KeyBinding{key: key, shift: true} }
+	tests := []struct {
+		name string
+		keys []KeyBinding
+		want string
+	}{
+		{"none", nil, ""},
+		{"one", []KeyBinding{r('s')}, "s"},
+		{"all fit", []KeyBinding{r('h'), r('4'), {key: tcell.KeyLeft}}, "h 4 Left"},
+		{"long key", []KeyBinding{shift(tcell.KeyRight)}, "Shift-Right"},
+		{"long key and more", []KeyBinding{shift(tcell.KeyRight), r('l')}, "Shift-Right…"},
+		{"some left out", []KeyBinding{r('a'), r('b'), shift(tcell.KeyRight)}, "a b …"},
+		{"too long", []KeyBinding{shift(tcell.KeyBackspace2)}, "Shift-Backs…"},
+	}
+	for _, tt := range tests {
+		if got := keyList(tt.keys, 12); got != tt.want {
+			t.Errorf("%s: keyList() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,12 +1,10 @@
 package main
 
-import (
-	"fmt"
-	"math/rand"
-)
+import "fmt"
 
+// Leaves room for the message and status lines on an 80x24 terminal
 const (
-	MapMaxX, MapMaxY = 80, 23
+	MapMaxX, MapMaxY = 80, 22
 )
 
 // If set to true, draw corridors without accouting for existing tiles
@@ -30,6 +28,28 @@ const (
 	TileStairsUp
 )
 
+var TileNames = map[TileType]string{
+	TileEmpty:    "solid rock",
+	TileWallH:    "a wall",
+	TileWallV:    "a wall",
+	TileWallUL:   "a wall",
+	TileWallUR:   "a wall",
+	TileWallLL:   "a wall",
+	TileWallLR:   "a wall",
+	TileFloor:    "the floor of a room",
+	TileCorridor: "a corridor",
+	TileDoor:     "a doorway",
+	TileStairsDn: "a staircase down",
+	TileStairsUp: "a staircase up",
+}
+
+// The display style of each tile, anything not listed uses "default"
+var TileStyles = map[TileType]string{
+	TileDoor:     "brown",
+	TileStairsDn: "green",
+	TileStairsUp: "green",
+}
+
 // -----------------------------------------------------------------------
 type Tile struct {
 	typ     TileType
@@ -165,7 +185,7 @@ func (m *DungeonMap) RandDirectionCoords(orig Coord) Coord {
 	}
 
 	if len(cList) > 0 {
-		idx := rand.Intn(len(cList))
+		idx := rng.Intn(len(cList))
 		return cList[idx]
 	} else {
 		return Coord{0, 0}
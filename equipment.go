@@ -1,18 +1,22 @@
 package main
 
-import (
-	"fmt"
-	"math/rand"
-)
+import "fmt"
 
 // === WEAPONS ===========================================================
 
 type Weapon struct {
-	name   string
-	damage Dice
-	ench   int
-	cursed bool
-	worth  int
+	name     string
+	damage   Dice
+	thrown   Dice
+	launcher string
+	stacks   bool
+	qty      int
+	ench     int
+	cursed   bool
+	worth    int
+	known    bool // enchantment and curse have been identified
+	revealed bool // curse has been revealed (e.g. by wielding it)
+	usage    int  // turns spent wielded, see FamiliarTime
 }
 
 // -----------------------------------------------------------------------
@@ -23,25 +27,28 @@ func newWeapon(name string) *Weapon {
 	}
 
 	return &Weapon{
-		name:   name,
-		damage: parseDice(t.melee)[0],
-		worth:  t.worth,
+		name:     name,
+		damage:   parseDice(t.melee)[0],
+		thrown:   parseDice(t.thrown)[0],
+		launcher: t.launcher,
+		stacks:   t.stacks,
+		qty:      1,
+		worth:    t.worth,
 	}
 }
 
 // -----------------------------------------------------------------------
 func randWeapon() *Weapon {
 	// Pick a weapon from the list at random
-	i := rand.Intn(len(WeaponLib))
-	var w *Weapon
-	for name := range WeaponLib {
-		if i == 0 {
-			w = newWeapon(name)
-		}
-		i--
-	}
+	names := libNames(WeaponLib)
+	w := newWeapon(names[rng.Intn(len(names))])
 	w.ench, w.cursed = randEnchant(5, 10)
 
+	// Ammunition is found in bundles of 8-15
+	if w.stacks {
+		w.qty = rng.Intn(8) + 8
+	}
+
 	return w
 }
 
@@ -52,27 +59,32 @@ func (w *Weapon) Equip(p *Player, msg *MessageLog) bool {
 		return false
 	}
 	if p.equiped["weapon"] != nil {
-		msg.Add("You need to put away the %v first.", p.equiped["weapon"])
+		msg.Add(MsgItem, "You need to put away the %v first.", p.equiped["weapon"])
 		return false
 	}
 	p.equiped["weapon"] = w
 	p.Melee = w.damage
-	msg.Add("You are now wielding the %v.", w)
+	msg.Add(MsgItem, "You are now wielding the %v.", w)
+	if w.cursed && !w.revealed {
+		w.revealed = true
+		msg.Add(MsgDanger, "Your hand tingles as the %v grips it. It's cursed!", w)
+	}
 	return true
 }
 
 // -----------------------------------------------------------------------
 func (w *Weapon) Unequip(p *Player, msg *MessageLog) bool {
 	if p.equiped["weapon"] == nil {
-		msg.Add("You aren't wielding the %v.", w)
+		msg.Add(MsgItem, "You aren't wielding the %v.", w)
 		return false
 	}
 	if w.cursed {
-		msg.Add("You cannot put away the %v, it's cursed!", w)
+		w.revealed = true
+		msg.Add(MsgItem, "You cannot put away the %v, it's cursed!", w)
 		return false
 	}
 	p.equiped["weapon"] = nil
-	msg.Add("You put away the %v.", w)
+	msg.Add(MsgItem, "You put away the %v.", w)
 	return true
 }
 
@@ -82,52 +94,146 @@ func (w *Weapon) Rune() rune {
 }
 
 func (w *Weapon) GndString() string {
-	return fmt.Sprintf("a %s", w)
+	if w.qty > 1 {
+		return fmt.Sprintf("%d %ss", w.qty, w)
+	}
+	return fmt.Sprintf("%s %s", article(w.name), w)
 }
 
 func (w *Weapon) InvString() string {
 	cursed := ""
-	if w.cursed {
+	if w.cursed && (w.known || w.revealed) {
 		cursed = " {cursed}"
 	}
-	return fmt.Sprintf("%+d %v [%s]%s", w.ench, w, w.damage, cursed)
+	name := w.String()
+	if w.qty > 1 {
+		name = fmt.Sprintf("%d %ss", w.qty, w)
+	}
+	dice := w.damage
+	if w.IsMissile() {
+		dice = w.thrown
+	}
+	if !w.known {
+		return fmt.Sprintf("%v [%s]%s", name, dice, cursed)
+	}
+	return fmt.Sprintf("%+d %v [%s]%s", w.ench, name, dice, cursed)
 }
 
 func (w *Weapon) Worth() int {
 	if w.ench < 0 {
 		return 0
 	} else {
-		return (1 + (10 * w.ench)) * w.worth
+		return (1 + (10 * w.ench)) * w.worth * w.qty
 	}
 }
 
+// -----------------------------------------------------------------------
+// Missiles are weapons meant to be fired from a launcher (e.g. arrows)
+func (w *Weapon) IsMissile() bool {
+	return w.launcher != ""
+}
+
+// -----------------------------------------------------------------------
+// implement the Stackable interface
+
+func (w *Weapon) Count() int {
+	return w.qty
+}
+
+func (w *Weapon) AdjustCount(amt int) {
+	w.qty += amt
+}
+
+func (w *Weapon) StacksWith(item Item) bool {
+	w2, ok := item.(*Weapon)
+	return ok && w.stacks && w.name == w2.name && w.ench == w2.ench &&
+		w.cursed == w2.cursed && w.known == w2.known
+}
+
+func (w *Weapon) Split(qty int) Item {
+	w2 := *w
+	w2.qty = qty
+	w.qty -= qty
+	return &w2
+}
+
+// Returns the damage dice when thrown and the bonus from the launcher (if
+// any).  Thrown damage only applies if the weapon isn't meant to be fired
+// or the proper launcher is being wielded, otherwise it is simply the melee
+// damage (e.g. throwing arrows by hand).
+func (w *Weapon) ThrownDamage(wielded Equipable) (Dice, int) {
+	if !w.IsMissile() {
+		return w.thrown, 0
+	}
+	if w.FiredFrom(wielded) {
+		return w.thrown, wielded.(*Weapon).ench
+	}
+	return w.damage, 0
+}
+
+// Returns true if the weapon is a missile and the wielded weapon is its
+// launcher (e.g. an arrow and a short bow)
+func (w *Weapon) FiredFrom(wielded Equipable) bool {
+	launcher, ok := wielded.(*Weapon)
+	return ok && w.IsMissile() && launcher.name == w.launcher
+}
+
+func (w *Weapon) IsIdentified() bool {
+	return w.known
+}
+
+func (w *Weapon) Identify() {
+	w.known = true
+}
+
+// Called each turn the weapon is wielded.  Returns true once the player
+// has become familiar enough with it to identify it.
+func (w *Weapon) Practice() bool {
+	w.usage++
+	if !w.known && w.usage >= FamiliarTime {
+		w.known = true
+		return true
+	}
+	return false
+}
+
 func (w Weapon) String() string {
 	return w.name
 }
 
 // -----------------------------------------------------------------------
 type WeaponTemplate struct {
-	melee  string
-	thrown string
-	worth  int
+	melee    string
+	thrown   string
+	worth    int
+	launcher string // name of the weapon needed to fire this one
+	stacks   bool   // ammunition that comes in bundles
 }
 
 var WeaponLib = map[string]WeaponTemplate{
-	"mace":             {"2d4", "1d3", 9},
-	"long sword":       {"1d10", "1d2", 15},
-	"dagger":           {"1d6", "1d4", 2},
-	"two-handed sword": {"3d6", "1d2", 30},
-	"spear":            {"1d8", "1d6", 2},
+	"mace":             {"2d4", "1d3", 9, "", false},
+	"long sword":       {"1d10", "1d2", 15, "", false},
+	"dagger":           {"1d6", "1d4", 2, "", false},
+	"two-handed sword": {"3d6", "1d2", 30, "", false},
+	"spear":            {"1d8", "1d6", 2, "", false},
+	"short bow":        {"1d1", "1d1", 15, "", false},
+	"arrow":            {"1d1", "2d3", 1, "short bow", true},
+	"dart":             {"1d1", "1d3", 1, "", true},
+	"crossbow":         {"1d1", "1d1", 30, "", false},
+	"crossbow bolt":    {"1d2", "2d5", 1, "crossbow", true},
 }
 
 // === ARMOR =============================================================
 
 type Armor struct {
-	Name   string
-	AC     int
-	ench   int
-	cursed bool
-	worth  int
+	Name     string
+	AC       int
+	ench     int
+	cursed   bool
+	worth    int
+	known    bool // enchantment and curse have been identified
+	revealed bool // curse has been revealed (e.g. by wearing it)
+	usage    int  // turns spent worn, see FamiliarTime
 }
 
 // -----------------------------------------------------------------------
@@ -147,14 +253,8 @@ func newArmor(name string) *Armor {
 // -----------------------------------------------------------------------
 func randArmor() *Armor {
 	// Pick an armor from the list at random
-	i := rand.Intn(len(ArmorLib))
-	var a *Armor
-	for name := range ArmorLib {
-		if i == 0 {
-			a = newArmor(name)
-		}
-		i--
-	}
+	names := libNames(ArmorLib)
+	a := newArmor(names[rng.Intn(len(names))])
 	a.ench, a.cursed = randEnchant(8, 20)
 	return a
 }
@@ -166,28 +266,33 @@ func (a *Armor) Equip(p *Player, msg *MessageLog) bool {
 		return false
 	}
 	if p.equiped["armor"] != nil {
-		msg.Add("You need to take off the %v first.", p.equiped["armor"])
+		msg.Add(MsgItem, "You need to take off the %v first.", p.equiped["armor"])
 		return false
 	}
 	p.equiped["armor"] = a
 	p.AC = a.AC - a.ench
-	msg.Add("You are now wearing the %v.", a)
+	msg.Add(MsgItem, "You are now wearing the %v.", a)
+	if a.cursed && !a.revealed {
+		a.revealed = true
+		msg.Add(MsgDanger, "The %v tightens around you. It's cursed!", a)
+	}
 	return true
 }
 
 // -----------------------------------------------------------------------
 func (a *Armor) Unequip(p *Player, msg *MessageLog) bool {
 	if p.equiped["armor"] == nil {
-		msg.Add("You aren't wearing the %v.", a)
+		msg.Add(MsgItem, "You aren't wearing the %v.", a)
 		return false
 	}
 	if a.cursed {
-		msg.Add("You cannot take off the %v, it's cursed!", a)
+		a.revealed = true
+		msg.Add(MsgItem, "You cannot take off the %v, it's cursed!", a)
 		return false
 	}
 	p.equiped["armor"] = nil
 	p.AC = 10
-	msg.Add("You take off the %v.", a)
+	msg.Add(MsgItem, "You take off the %v.", a)
 	return true
 }
 
@@ -202,9 +307,12 @@ func (a *Armor) GndString() string {
 
 func (a *Armor) InvString() string {
 	cursed := ""
-	if a.cursed {
+	if a.cursed && (a.known || a.revealed) {
 		cursed = " {cursed}"
 	}
+	if !a.known {
+		return fmt.Sprintf("%v [%d]%s", a, a.AC, cursed)
+	}
 	return fmt.Sprintf("%+d %v [%d]%s", a.ench, a, a.AC-a.ench, cursed)
 }
 
@@ -216,6 +324,25 @@ func (a *Armor) Worth() int {
 	}
 }
 
+func (a *Armor) IsIdentified() bool {
+	return a.known
+}
+
+func (a *Armor) Identify() {
+	a.known = true
+}
+
+// Called each turn the armor is worn.  Returns true once the player has
+// become familiar enough with it to identify it.
+func (a *Armor) Practice() bool {
+	a.usage++
+	if !a.known && a.usage >= FamiliarTime {
+		a.known = true
+		return true
+	}
+	return false
+}
+
 func (a Armor) String() string {
 	return a.Name
 }
@@ -237,14 +364,17 @@ var ArmorLib = map[string]ArmorTemplate{
 
 // =======================================================================
 
+// Number of turns an item needs to be used before it is identified
+const FamiliarTime = 300
+
 func randEnchant(enchantProb int, cursedProb int) (int, bool) {
 	// 10% chance of a cursed weapon with -1 to -3 penalty, and a 5% chance
 	// of an enchanted weapon with a +1 to +3 bonus.
 	var ench int
-	if rand.Intn(100) < enchantProb { // enchanted
-		ench = rand.Intn(2) + 1
-	} else if rand.Intn(100) < cursedProb { // cursed
-		ench = -1 * (rand.Intn(2) + 1)
+	if rng.Intn(100) < enchantProb { // enchanted
+		ench = rng.Intn(2) + 1
+	} else if rng.Intn(100) < cursedProb { // cursed
+		ench = -1 * (rng.Intn(2) + 1)
 	}
 	cursed := false
 	if ench < 0 {
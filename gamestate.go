@@ -1,6 +1,9 @@
 package main
 
-import "math/rand"
+import (
+	"fmt"
+	"math/rand"
+)
 
 type GameState struct {
 	done           bool
@@ -12,10 +15,25 @@ type GameState struct {
 	wander         int
 	spawnFoodTimer int
 	items          ItemList
+	seenItems      ItemList // what the player last saw on each tile
+	lastItemPos    Coord    // where the player last checked for items
+	seed           int64
+	rng            *rand.Rand
+	kills          map[string]int // number of each monster defeated, by name
 }
 
+// The random source of the game being played, for the helpers that don't
+// have the game state at hand (e.g. Dice.Roll).  Set by GameState.Init.
+var rng = rand.New(rand.NewSource(1))
+
 // -----------------------------------------------------------------------
-func (gs *GameState) Init() {
+func (gs *GameState) Init(seed int64) {
+
+	// Everything random is drawn from the game's own source so the same seed
+	// always gives the same game
+	gs.seed = seed
+	gs.rng = rand.New(rand.NewSource(seed))
+	rng = gs.rng
 
 	assignPotionColors()
 
@@ -23,7 +41,9 @@ func (gs *GameState) Init() {
 	gs.player = &Player{}
 	gs.monsters = &MonsterList{}
 	gs.items = ItemList{}
+	gs.seenItems = ItemList{}
 	gs.messages = &MessageLog{}
+	gs.kills = make(map[string]int)
 	gs.wander = WanderTimer
 	gs.spawnFoodTimer = SpawnFood
 
@@ -33,10 +53,12 @@ func (gs *GameState) Init() {
 	gs.player.Pickup(newFood("ration"))
 
 	weap := newWeapon("mace")
+	weap.Identify()
 	gs.player.Pickup(weap)
 	weap.Equip(gs.player, gs.messages)
 
 	armor := newArmor("ring mail")
+	armor.Identify()
 	gs.player.Pickup(armor)
 	armor.Equip(gs.player, gs.messages)
 
@@ -50,7 +72,7 @@ func (gs *GameState) Init() {
 	gs.UpdatePlayerFOV()
 
 	gs.messages.Clear() // equipping will create messages which we don't want here
-	gs.messages.Add("Welcome to the Dungeons of Doom!")
+	gs.messages.Add(MsgSystem, "Welcome to the Dungeons of Doom!")
 }
 
 // -----------------------------------------------------------------------
@@ -64,7 +86,7 @@ func (gs *GameState) MoveActor(a Actor, delta Coord) bool {
 
 	// Check edges of the map
 	if gs.dungeon.IsOutOfBounds(dest) {
-		gs.messages.Add("As you gaze into the abyss, it begins to gaze back into you...")
+		gs.messages.Add(MsgSystem, "As you gaze into the abyss, it begins to gaze back into you...")
 		return false
 	}
 
@@ -89,8 +111,8 @@ func (gs *GameState) MoveActor(a Actor, delta Coord) bool {
 
 	case *Player:
 		// Check if player is paralyzed
-		if gs.player.Timer("paralyzed") > 0 {
-			gs.messages.Add("You remain unable to move.")
+		if gs.player.IsParalyzed() {
+			gs.messages.Add(MsgStatus, "You remain unable to move.")
 			return true
 		}
 
@@ -98,7 +120,7 @@ func (gs *GameState) MoveActor(a Actor, delta Coord) bool {
 		m := gs.monsters.MonsterAt(dest)
 		if m != nil {
 			a.Attack(m, gs.messages)
-			m.State = StateChase
+			m.Disturb(gs.messages)
 			return true
 		}
 	}
@@ -115,16 +137,16 @@ func (gs *GameState) MoveActor(a Actor, delta Coord) bool {
 // -----------------------------------------------------------------------
 func (gs *GameState) GoDownstairs() bool {
 	if gs.player.IsParalyzed() {
-		gs.messages.Add("You remain unable to move.")
+		gs.messages.Add(MsgStatus, "You remain unable to move.")
 		return true
 	}
 
 	if gs.dungeon.TileTypeAt(gs.player.Pos()) == TileStairsDn || debugFlag["main"] {
-		gs.messages.Add("You descend the ancient stairs.")
+		gs.messages.Add(MsgSystem, "You descend the ancient stairs.")
 		generateRandomLevel(gs)
 		return true
 	} else {
-		gs.messages.Add("There are no stairs to go down here.")
+		gs.messages.Add(MsgSystem, "There are no stairs to go down here.")
 		return false
 	}
 }
@@ -132,75 +154,397 @@ func (gs *GameState) GoDownstairs() bool {
 // -----------------------------------------------------------------------
 func (gs *GameState) GoUpstairs() bool {
 	if gs.player.IsParalyzed() {
-		gs.messages.Add("You remain unable to move.")
+		gs.messages.Add(MsgStatus, "You remain unable to move.")
 		return true
 	}
 
 	if gs.dungeon.TileTypeAt(gs.player.Pos()) == TileStairsUp {
-		gs.messages.Add("Your way is magically blocked.")
+		gs.messages.Add(MsgSystem, "Your way is magically blocked.")
 	} else {
-		gs.messages.Add("There are no stairs to go up here.")
+		gs.messages.Add(MsgSystem, "There are no stairs to go up here.")
+	}
+	return false
+}
+
+// -----------------------------------------------------------------------
+// Throws the given inventory item in a direction until it hits a monster or
+// something solid, then lands on the floor (unless it shatters).
+func (gs *GameState) ThrowItem(idx int, dir Coord) bool {
+	p := gs.player
+	item := p.inventory[idx]
+
+	if p.IsEquipped(item) {
+		gs.messages.Add(MsgItem, "You can't throw something you are using.")
+		return false
+	}
+
+	// Only throw one from a stack
+	item = p.RemoveItem(idx, 1)
+
+	// Follow the path of the projectile until it hits something or runs
+	// out of range
+	pos := p.Pos()
+	var target *Monster
+	for dist := p.ThrowRange(item); target == nil && dist > 0; dist-- {
+		next := pos.Sum(dir)
+		if !gs.dungeon.IsWalkable(pos, next) {
+			break
+		}
+		pos = next
+		target = gs.monsters.MonsterAt(pos)
+	}
+
+	label := "something"
+	if target != nil && !p.IsBlind() {
+		label = "the " + target.String()
+	}
+
+	switch item := item.(type) {
+	case *Potion:
+		gs.messages.Add(MsgItem, "The flask shatters.")
+		if target != nil {
+			doMonsterEffect(PotionLib[item.id].effect, target, gs)
+			target.Disturb(gs.messages)
+		}
+		return true
+
+	case *Weapon:
+		if target != nil {
+			dice, bonus := item.ThrownDamage(p.equiped["weapon"])
+			bonus += item.ench
+			if attackHits(p.ToHit()-bonus, target.ArmorClass()) {
+				dmg := dice.Add(bonus + p.StrDamageBonus()).Roll()
+				if dmg < 1 {
+					dmg = 1
+				}
+				target.AdjustHP(-dmg)
+				gs.messages.Add(MsgCombat, "The %v hits %s for %d damage.", item, label, dmg)
+			} else {
+				gs.messages.Add(MsgCombat, "The %v misses %s.", item, label)
+			}
+			target.Disturb(gs.messages)
+		}
+	}
+
+	gs.DropItemNear(pos, item)
+	return true
+}
+
+// -----------------------------------------------------------------------
+// Places an item on the floor at the given position or, if there is already
+// something there, on a free neighbouring tile.  If there is no room the item
+// is lost.
+func (gs *GameState) DropItemNear(pos Coord, item Item) bool {
+	spots := append([]Coord{pos}, gs.dungeon.getWalkableNeighbours(pos)...)
+	for _, c := range spots {
+		if c == gs.player.Pos() {
+			continue
+		}
+		other, taken := gs.items[c]
+		if !taken {
+			gs.items[c] = item
+			return true
+		}
+		if s, ok := other.(Stackable); ok && s.StacksWith(item) {
+			s.AdjustCount(item.(Stackable).Count())
+			return true
+		}
 	}
+	gs.messages.Add(MsgItem, "The %v vanishes as it hits the ground.", item)
 	return false
 }
 
 // -----------------------------------------------------------------------
+// Drops an item from the player's inventory onto the floor.  Like the
+// original, only one item of a stack is dropped except for weapons (i.e.
+// ammunition) which are dropped as a bundle.
+func (gs *GameState) DropItem(idx int) bool {
+	p := gs.player
+	item := p.inventory[idx]
+
+	if _, taken := gs.items[p.Pos()]; taken {
+		gs.messages.Add(MsgItem, "There is already something there.")
+		return false
+	}
+
+	if p.IsEquipped(item) {
+		if !item.(Equipable).Unequip(p, gs.messages) {
+			return false
+		}
+	}
+
+	qty := 1
+	if w, ok := item.(*Weapon); ok {
+		qty = w.Count()
+	}
+	item = p.RemoveItem(idx, qty)
+	gs.items[p.Pos()] = item
+	gs.lastItemPos = p.Pos() // don't pick it right back up
+	gs.messages.Add(MsgItem, "You drop %v.", item.GndString())
+	return true
+}
+
+// -----------------------------------------------------------------------
+func (gs *GameState) PickupItem() bool {
+	pos := gs.player.Pos()
+	item, ok := gs.items[pos]
+	if !ok {
+		gs.messages.Add(MsgItem, "There is nothing here to pick up.")
+		return false
+	}
+	if gs.player.Pickup(item) {
+		gs.messages.Add(MsgItem, "You pick up %v.", item.GndString())
+		delete(gs.items, pos)
+		return true
+	}
+	gs.messages.Add(MsgItem, "Your pack is too full to pick up %v.", item.GndString())
+	return false
+}
+
+// -----------------------------------------------------------------------
+// Called each time through the main loop, but only does anything once the
+// player has stepped onto a new tile.
 func (gs *GameState) CheckItems() {
-	for pos, item := range gs.items {
-		if pos == gs.player.Pos() {
-			if gs.player.Pickup(item) {
-				gs.messages.Add("You pick up %v.", item.GndString())
-				delete(gs.items, pos)
+	pos := gs.player.Pos()
+	if pos == gs.lastItemPos {
+		return
+	}
+	gs.lastItemPos = pos
+
+	item, ok := gs.items[pos]
+	if !ok {
+		return
+	}
+	if autoPickup[item.Rune()] {
+		gs.PickupItem()
+	} else {
+		gs.messages.Add(MsgItem, "There is %v here.", item.GndString())
+	}
+}
+
+// -----------------------------------------------------------------------
+// Returns the number of monsters the player can currently see
+func (gs *GameState) VisibleMonsters() int {
+	return len(gs.SeenMonsters())
+}
+
+// Returns the monsters the player can currently see
+func (gs *GameState) SeenMonsters() []*Monster {
+	if gs.player.IsBlind() {
+		return nil
+	}
+	var seen []*Monster
+	for _, m := range *gs.monsters {
+		if gs.dungeon.CanSee(m) {
+			seen = append(seen, m)
+		}
+	}
+	return seen
+}
+
+// -----------------------------------------------------------------------
+// Decides which way the player should keep running after taking a step in
+// the given direction.  Returns false if there is something interesting here
+// (an item, door, stairs or a fork in a corridor).  Corridors are followed
+// around corners.
+func (gs *GameState) RunDirection(dir Coord) (Coord, bool) {
+	pos := gs.player.Pos()
+	if _, ok := gs.items[pos]; ok {
+		return dir, false
+	}
+
+	switch gs.dungeon.TileTypeAt(pos) {
+	case TileDoor, TileStairsDn, TileStairsUp:
+		return dir, false
+
+	case TileCorridor:
+		back := pos.Diff(dir)
+		var exits []Coord
+		for _, nb := range gs.dungeon.getWalkableNeighbours(pos) {
+			if nb != back {
+				exits = append(exits, nb)
+			}
+		}
+		if len(exits) != 1 {
+			return dir, false
+		}
+		return exits[0].Diff(pos), true
+	}
+
+	return dir, gs.dungeon.IsWalkable(pos, pos.Sum(dir))
+}
+
+// -----------------------------------------------------------------------
+// Returns the direction of the next step when auto-exploring, heading for
+// the closest unexplored area or item that would be picked up.  Returns
+// false (with a message) if there is nothing left to explore.
+func (gs *GameState) ExploreStep() (Coord, bool) {
+	var targets []Coord
+	for x := 0; x < MapMaxX; x++ {
+		for y := 0; y < MapMaxY; y++ {
+			pos := Coord{x, y}
+			t := gs.dungeon.TileAt(pos)
+			if !t.visited && t.IsWalkable() {
+				targets = append(targets, pos)
+			} else if item, ok := gs.items[pos]; ok && t.visited && autoPickup[item.Rune()] {
+				if pos != gs.player.Pos() {
+					targets = append(targets, pos)
+				}
 			}
 		}
 	}
+
+	pos := gs.player.Pos()
+	dmap := newDMap(gs.dungeon, targets...)
+	if dist, ok := dmap.distance[pos]; !ok || dist == 0 {
+		gs.messages.Add(MsgSystem, "There is nothing left to explore here.")
+		return Coord{}, false
+	}
+	next := dmap.NextStep(pos)
+	return next.Diff(pos), true
+}
+
+// -----------------------------------------------------------------------
+// Describes what the player sees (or remembers) at the given position
+func (gs *GameState) Describe(pos Coord) string {
+	p := gs.player
+	t := gs.dungeon.TileAt(pos)
+	canSee := t.visible && !p.IsBlind()
+
+	if pos == p.Pos() {
+		return "You see yourself."
+	}
+	if m := gs.monsters.MonsterAt(pos); m != nil {
+		if canSee || p.status.Has(StatusDetMonsters) {
+			return fmt.Sprintf("You see %s %v (%s).", article(m.Name), m, m.HealthString())
+		}
+	}
+	if !t.visited {
+		return "You don't know what is there."
+	}
+	if !canSee {
+		if item, ok := gs.seenItems[pos]; ok {
+			return fmt.Sprintf("You remember %s on %s.", item.GndString(), TileNames[t.typ])
+		}
+		return fmt.Sprintf("You remember %s.", TileNames[t.typ])
+	}
+	if item, ok := gs.items[pos]; ok {
+		return fmt.Sprintf("You see %s on %s.", item.GndString(), TileNames[t.typ])
+	}
+	return fmt.Sprintf("You see %s.", TileNames[t.typ])
+}
+
+// Remembers the items on the tiles in view, or that they are gone, so the
+// player only knows about items they have seen
+func (gs *GameState) RememberItems() {
+	if gs.player.IsBlind() {
+		return
+	}
+	for x := 0; x < MapMaxX; x++ {
+		for y := 0; y < MapMaxY; y++ {
+			pos := Coord{x, y}
+			if !gs.dungeon.TileAt(pos).visible {
+				continue
+			}
+			if item, ok := gs.items[pos]; ok {
+				gs.seenItems[pos] = item
+			} else {
+				delete(gs.seenItems, pos)
+			}
+		}
+	}
+}
+
+// -----------------------------------------------------------------------
+// Returns the positions the player remembers with the given symbol, either
+// dungeon features (e.g. '>' for stairs) or items the player has seen.
+func (gs *GameState) RememberedPositions(r rune) []Coord {
+	var list []Coord
+	for x := 0; x < MapMaxX; x++ {
+		for y := 0; y < MapMaxY; y++ {
+			pos := Coord{x, y}
+			t := gs.dungeon.TileAt(pos)
+			if !t.visited {
+				continue
+			}
+			if item, ok := gs.seenItems[pos]; ok && item.Rune() == r {
+				list = append(list, pos)
+			} else if TileRunes[t.typ] == r && t.typ != TileEmpty {
+				list = append(list, pos)
+			}
+		}
+	}
+	return list
 }
 
 // -----------------------------------------------------------------------
 func (gs *GameState) PruneMonsters() {
-	for i, m := range *gs.monsters {
+	// Go backwards so removing a monster doesn't skip over the next one
+	for i := len(*gs.monsters) - 1; i >= 0; i-- {
+		m := (*gs.monsters)[i]
+		if m.vanished {
+			gs.monsters.Remove(i)
+			continue
+		}
 		if m.HP <= 0 {
 			gs.monsters.Remove(i)
 			if gs.player.IsBlind() {
-				gs.messages.Add("You defeated something!")
+				gs.messages.Add(MsgCombat, "You defeated something!")
 			} else {
-				gs.messages.Add("You defeated the %s!", m.Name)
+				gs.messages.Add(MsgCombat, "You defeated the %s!", m.Name)
 			}
 			gs.player.AddXP(m.XP)
+			gs.kills[m.Name]++
 		}
 	}
 	// This is the only place XP is awarded so check player level
 	msg := gs.player.CheckLevel()
-	gs.messages.Add(msg)
+	gs.messages.Add(MsgStatus, "%s", msg)
 }
 
 // -----------------------------------------------------------------------
 func (gs *GameState) MonstersAct() {
 
 	for _, m := range *gs.monsters {
-		switch m.State {
-
-		case StateDormant:
-			if m.isMean && gs.dungeon.CanSee(m) && rand.Intn(100) < 67 {
-				m.State = StateChase
+		m.AdjustEnergy(m.Speed())
+		for canAct(m) && !m.vanished && gs.player.HP > 0 {
+			m.AdjustEnergy(-ActionCost)
+			if !m.IsAsleep() {
+				gs.MonsterAct(m)
 			}
+		}
+		m.Update(gs.messages)
+	}
+}
 
-		case StateChase:
+// -----------------------------------------------------------------------
+func (gs *GameState) MonsterAct(m *Monster) {
 
-			if m.randMove > rand.Intn(100) {
-				// Move randomly randMove% of the time (e.g. bats)
-				delta := gs.dungeon.RandDirectionCoords(m.Pos())
-				gs.MoveActor(m, delta)
+	switch m.State {
 
-			} else {
-				// Pathfinding to the player is already calculated with the dmap
-				m.nextStep = gs.dmap.NextStep(m.Pos())
-				delta := m.DirectionCoordsTo(m.nextStep)
-				gs.MoveActor(m, delta)
+	case StateDormant:
+		// Blind monsters won't notice the player
+		if m.isMean && !m.IsBlind() && gs.dungeon.CanSee(m) && gs.rng.Intn(100) < 67 {
+			m.State = StateChase
+		}
 
-				// For testing, store the next step
-				m.nextStep = gs.dmap.NextStep(Coord{m.X, m.Y})
-			}
+	case StateChase:
+
+		if m.randMove > gs.rng.Intn(100) || m.IsBlind() {
+			// Move randomly randMove% of the time (e.g. bats) or when the
+			// monster can't see where the player is
+			delta := gs.dungeon.RandDirectionCoords(m.Pos())
+			gs.MoveActor(m, delta)
+
+		} else {
+			// Pathfinding to the player is already calculated with the dmap
+			// (MoveActor will override the direction if confused)
+			m.nextStep = gs.dmap.NextStep(m.Pos())
+			delta := m.DirectionCoordsTo(m.nextStep)
+			gs.MoveActor(m, delta)
+
+			// For testing, store the next step
+			m.nextStep = gs.dmap.NextStep(Coord{m.X, m.Y})
 		}
 	}
 }
@@ -223,7 +567,7 @@ func (gs *GameState) UpdatePlayerFOV() {
 			gs.dungeon.SetVisible(r.TopLeft(), r.W+1, r.H+1, true)
 		}
 	}
-
+	gs.RememberItems()
 }
 
 // -----------------------------------------------------------------------
@@ -234,13 +578,13 @@ func (gs *GameState) WanderingMonsters() {
 	if gs.wander > 0 {
 		gs.wander--
 	} else {
-		if gs.player.moves%4 == 0 && rand.Intn(100) < 16 {
+		if gs.player.moves%4 == 0 && gs.rng.Intn(100) < 16 {
 
 			// Find a random room that the player is not in
-			r := rand.Intn(len(gs.dungeon.rooms))
+			r := gs.rng.Intn(len(gs.dungeon.rooms))
 			rm := gs.dungeon.rooms[r]
 			for rm.InRoom(gs.player.Pos()) {
-				r = rand.Intn(len(gs.dungeon.rooms))
+				r = gs.rng.Intn(len(gs.dungeon.rooms))
 				rm = gs.dungeon.rooms[r]
 			}
 
@@ -260,6 +604,26 @@ func (gs *GameState) WanderingMonsters() {
 	}
 }
 
-func (gs *GameState) IsBonusMove() bool {
-	return gs.player.IsHasted() && gs.player.moves%2 == 0
+// -----------------------------------------------------------------------
+// Called once the player has used up an action.  Advances game time one turn
+// at a time until the player has enough energy to act again.
+func (gs *GameState) EndPlayerTurn() {
+	gs.player.AdjustEnergy(-ActionCost)
+	gs.PruneMonsters()
+	for !canAct(gs.player) && gs.player.HP > 0 {
+		gs.Tick()
+	}
+}
+
+// -----------------------------------------------------------------------
+// A single game turn.  Every actor gains energy based on their speed and
+// monsters act as many times as their energy allows.
+func (gs *GameState) Tick() {
+	// Everything that happens from here on is part of the next turn
+	gs.messages.SetTurn(gs.player.moves + 1)
+	gs.player.Update(gs.messages)
+	gs.player.AdjustEnergy(gs.player.Speed())
+	gs.MonstersAct()
+	gs.PruneMonsters()
+	gs.WanderingMonsters()
 }
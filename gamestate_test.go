@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// A game state with just the player, enough to run the scheduler
+func newTestState() *GameState {
+	gs := &GameState{
+		player:    &Player{},
+		monsters:  &MonsterList{},
+		messages:  &MessageLog{},
+		items:     ItemList{},
+		seenItems: ItemList{},
+		rng:       rand.New(rand.NewSource(1)),
+		wander:    1 << 30, // no wandering monsters
+	}
+	gs.player.Init()
+	return gs
+}
+
+func TestAdjustSpeed(t *testing.T) {
+	tests := []struct {
+		name   string
+		speed  int
+		hasted bool
+		slowed bool
+		want   int
+	}{
+		{"normal", NormalSpeed, false, false, 12},
+		{"hasted", NormalSpeed, true, false, 24},
+		{"slowed", NormalSpeed, false, true, 6},
+		{"bat", NormalSpeed * 3 / 2, false, false, 18},
+		{"slowed bat", NormalSpeed * 3 / 2, false, true, 9},
+		{"hasted zombie", NormalSpeed / 2, true, false, 12},
+	}
+	for _, tt := range tests {
+		if got := adjustSpeed(tt.speed, tt.hasted, tt.slowed); got != tt.want {
+			t.Errorf("%s: adjustSpeed() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+// The number of game turns that pass while the player takes their actions
+func TestEndPlayerTurn(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  StatusType
+		actions int
+		turns   int
+	}{
+		{"normal", -1, 10, 10},
+		{"hasted", StatusHaste, 10, 5},
+		{"slowed", StatusSlow, 10, 20},
+	}
+	for _, tt := range tests {
+		gs := newTestState()
+		if tt.status != -1 {
+			gs.player.ApplyStatus(tt.status, 1000, gs.messages)
+		}
+		for i := 0; i < tt.actions; i++ {
+			gs.EndPlayerTurn()
+		}
+		if gs.player.moves != tt.turns {
+			t.Errorf("%s: %d actions took %d turns, want %d", tt.name, tt.actions, gs.player.moves, tt.turns)
+		}
+	}
+}
+
+func TestThrowRange(t *testing.T) {
+	tests := []struct {
+		name    string
+		item    string
+		wielded string
+		str     int
+		want    int // where the item lands
+	}{
+		{"dart", "dart", "mace", 16, 9},
+		{"weak", "dart", "mace", 4, 3},
+		{"arrow by hand", "arrow", "mace", 16, 9},
+		{"arrow from bow", "arrow", "short bow", 16, 17},
+		{"hits the wall", "arrow", "short bow", 30, 28},
+	}
+	for _, tt := range tests {
+		gs := newTestState()
+		gs.dungeon = newTestMap([]string{
+			"------------------------------",
+			"|............................|",
+			"------------------------------",
+		}, 30)
+		gs.player.SetPos(Coord{1, 1})
+		gs.player.Str = tt.str
+		w := newWeapon(tt.wielded)
+		gs.player.Pickup(w)
+		w.Equip(gs.player, gs.messages)
+		gs.player.Pickup(newWeapon(tt.item))
+
+		gs.ThrowItem(len(gs.player.inventory)-1, Coord{1, 0})
+		if _, ok := gs.items[Coord{tt.want, 1}]; !ok || len(gs.items) != 1 {
+			t.Errorf("%s: the %s landed at %v, want x=%d", tt.name, tt.item, gs.items, tt.want)
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	gs := newTestState()
+	gs.dungeon = newTestMap([]string{
+		"-------",
+		"|.....|",
+		"-------",
+	}, 5)
+	gs.player.SetPos(Coord{1, 1})
+	apple, ration := &Food{name: "apple", qty: 1}, &Food{name: "ration", qty: 1}
+
+	// The player sees the apple, then walks away and it is taken
+	gs.items[Coord{2, 1}] = apple
+	gs.dungeon.tiles[2][1].visible = true
+	gs.RememberItems()
+	gs.dungeon.tiles[2][1].visible = false
+	delete(gs.items, Coord{2, 1})
+
+	// The ration is dropped somewhere the player can't see
+	gs.items[Coord{3, 1}] = ration
+
+	// And the one in view is there
+	gs.items[Coord{4, 1}] = ration
+	gs.dungeon.tiles[4][1].visible = true
+
+	tests := []struct {
+		name string
+		pos  Coord
+		want string
+	}{
+		{"player", Coord{1, 1}, "You see yourself."},
+		{"remembered item", Coord{2, 1}, "You remember an apple on the floor of a room."},
+		{"never seen", Coord{3, 1}, "You remember the floor of a room."},
+		{"in sight", Coord{4, 1}, "You see a ration on the floor of a room."},
+		{"unknown", Coord{5, 1}, "You don't know what is there."},
+	}
+	for _, tt := range tests {
+		if got := gs.Describe(tt.pos); got != tt.want {
+			t.Errorf("%s: Describe(%v) = %q, want %q", tt.name, tt.pos, got, tt.want)
+		}
+	}
+}
+
+func TestRememberItems(t *testing.T) {
+	gs := newTestState()
+	gs.dungeon = newTestMap([]string{
+		"-----",
+		"|...|",
+		"-----",
+	}, 5)
+	pos := Coord{2, 1}
+	gs.dungeon.tiles[2][1].visible = true
+
+	gs.items[pos] = &Food{name: "apple", qty: 1}
+	gs.RememberItems()
+	if got := gs.RememberedPositions('%'); len(got) != 1 || got[0] != pos {
+		t.Errorf("after seeing the apple RememberedPositions('%%') = %v, want [%v]", got, pos)
+	}
+
+	// Seeing the tile again without the apple forgets it
+	delete(gs.items, pos)
+	gs.RememberItems()
+	if got := gs.RememberedPositions('%'); len(got) != 0 {
+		t.Errorf("after the apple is gone RememberedPositions('%%') = %v, want none", got)
+	}
+}
+
+// Messages are stamped with the turn they happen on
+func TestMessageTurns(t *testing.T) {
+	gs := newTestState()
+	gs.player.ApplyStatus(StatusConfused, 2, gs.messages)
+
+	gs.messages.Add(MsgSystem, "first action")
+	gs.EndPlayerTurn()
+	gs.messages.Add(MsgSystem, "second action")
+	gs.EndPlayerTurn() // confusion wears off during the tick
+
+	want := []struct {
+		text string
+		turn int
+	}{
+		{"first action", 0},
+		{"second action", 1},
+		{"You feel less confused now.", 2},
+	}
+	msgs := gs.messages.messages
+	if len(msgs) != len(want) {
+		t.Fatalf("got %d messages, want %d: %v", len(msgs), len(want), msgs)
+	}
+	for i, w := range want {
+		if msgs[i].text != w.text || msgs[i].turn != w.turn {
+			t.Errorf("message %d = %q on turn %d, want %q on turn %d", i, msgs[i].text, msgs[i].turn, w.text, w.turn)
+		}
+	}
+}
+
+// The same seed gives the same level, items and monsters, even after the
+// monsters have had a few turns
+func TestInitSeed(t *testing.T) {
+	play := func(seed int64) string {
+		var gs GameState
+		gs.Init(seed)
+		for i := 0; i < 20; i++ {
+			gs.EndPlayerTurn()
+		}
+		str := morgueMap(&gs)
+		for x := 0; x < MapMaxX; x++ {
+			for y := 0; y < MapMaxY; y++ {
+				if item, ok := gs.items[Coord{x, y}]; ok {
+					str += item.GndString() + "\n"
+				}
+			}
+		}
+		for _, m := range gs.messages.messages {
+			str += m.String() + "\n"
+		}
+		return str
+	}
+	for _, seed := range []int64{1, 42, 12345} {
+		if first, second := play(seed), play(seed); first != second {
+			t.Errorf("seed %d gave two different games:\n%s\n%s", seed, first, second)
+		}
+	}
+}
+
+// Replaying a seed with status effects counting down on the player and the
+// monsters gives the same game each time
+func TestInitSeedStatus(t *testing.T) {
+	statusString := func(s StatusEffects) string {
+		str := ""
+		for _, st := range s.Active() {
+			str += fmt.Sprintf(" %s:%d", StatusLib[st].name, s.Turns(st))
+		}
+		return str
+	}
+	play := func(seed int64) string {
+		var gs GameState
+		gs.Init(seed)
+		p := gs.player
+		p.ApplyStatus(StatusConfused, 6, gs.messages) // these two wear off together
+		p.ApplyStatus(StatusBlind, 6, gs.messages)
+		p.ApplyStatus(StatusHaste, 4, gs.messages)
+		p.ApplyStatus(StatusHaste, 4, gs.messages) // faints for a random time
+		for _, m := range *gs.monsters {
+			m.ApplyStatus(StatusConfused, 10, gs.messages)
+			m.ApplyStatus(StatusSlow, 5, gs.messages)
+		}
+
+		str := ""
+		for i := 0; i < 12; i++ {
+			gs.EndPlayerTurn()
+			str += fmt.Sprintf("turn %d: player %d,%d hp %d%s\n", i, p.X, p.Y, p.HP, statusString(p.status))
+			for _, m := range *gs.monsters {
+				str += fmt.Sprintf("  %s %d,%d hp %d%s\n", m.Name, m.X, m.Y, m.HP, statusString(m.status))
+			}
+		}
+		for _, m := range gs.messages.messages {
+			str += m.String() + "\n"
+		}
+		return str
+	}
+	for _, seed := range []int64{1, 42, 12345} {
+		if first, second := play(seed), play(seed); first != second {
+			t.Errorf("seed %d gave two different games:\n%s\n%s", seed, first, second)
+		}
+	}
+}
@@ -1,9 +1,5 @@
 package main
 
-import (
-	"math/rand"
-)
-
 var graph *RoomGraph = &RoomGraph{}
 
 // ----------------------------------------------------------------------------
@@ -12,6 +8,7 @@ func generateRandomLevel(gs *GameState) {
 	gs.dungeon.Clear()
 	gs.monsters.Clear()
 	gs.items.Clear()
+	gs.seenItems.Clear()
 
 	graph = newRandomGraph()
 
@@ -21,6 +18,7 @@ func generateRandomLevel(gs *GameState) {
 
 	gs.player.SetPos(pos)
 	gs.player.depth++
+	gs.lastItemPos = Coord{-1, -1}
 	gs.spawnFoodTimer--
 
 	populateMonsters(gs)
@@ -40,20 +38,20 @@ func populateMonsters(gs *GameState) {
 		}
 
 		// 50% chance that any given room will have gold.
-		if rand.Intn(100) < 50 {
+		if rng.Intn(100) < 50 {
 			pos := r.RandPoint()
 			amt := randGoldAmt(gs.player.depth)
 			gs.items[pos] = newGold(amt)
 
 			// Rooms with gold have an 80% chance of having a monster.
-			if rand.Intn(100) < 80 {
+			if rng.Intn(100) < 80 {
 				m := randomMonster(gs.player.depth)
 				gs.monsters.Add(m, r.RandPoint())
 			}
 
 		} else {
 			// Rooms without gold have a 25% chance of having a monster.
-			if rand.Intn(100) < 25 {
+			if rng.Intn(100) < 25 {
 				m := randomMonster(gs.player.depth)
 				gs.monsters.Add(m, r.RandPoint())
 			}
@@ -66,7 +64,7 @@ func populateItems(gs *GameState) {
 
 	for i := 0; i < 9; i++ {
 
-		roll := rand.Intn(100) + 1
+		roll := rng.Intn(100) + 1
 		if roll > 35 {
 			//debug.Add("generate: no spawn (%d)", roll)
 			continue
@@ -189,7 +187,7 @@ func newRandomGraph() *RoomGraph {
 	}
 
 	// Add a few more connections to keep it interesting
-	n := rand.Intn(2) + 1 // 1-2
+	n := rng.Intn(2) + 1 // 1-2
 	for i := 0; i < n; i++ {
 		found := false
 		count = 0
@@ -287,7 +285,7 @@ func (g *RoomGraph) MakeCellBounds() (areas []Room) {
 
 	// assume 3x3 rooms on the map
 	roomW := (MapMaxX - 2) / 3 // 25
-	roomH := (MapMaxY - 2) / 3 // 6
+	roomH := (MapMaxY - 1) / 3 // 7
 
 	// split the map into 3x3 areas determine the bounds of each one
 	idx := 0
@@ -308,11 +306,11 @@ func (g *RoomGraph) MakeRandomRooms() {
 
 	// make a random room within each area
 	for i, a := range g.bounds {
-		//randW := rand.Intn(12) + 8    // between 8 and 20
-		randW := rand.Intn(a.W-5) + 5
-		randH := rand.Intn(a.H-4) + 4 // between 4 and max height of area
-		dx := rand.Intn(a.W - randW)  // position within the boundary area
-		dy := rand.Intn(a.H - randH)
+		//randW := rng.Intn(12) + 8    // between 8 and 20
+		randW := rng.Intn(a.W-5) + 5
+		randH := rng.Intn(a.H-4) + 4 // between 4 and max height of area
+		dx := rng.Intn(a.W - randW)  // position within the boundary area
+		dy := rng.Intn(a.H - randH)
 		g.rooms[i].SetSize(a.X+dx, a.Y+dy, randW, randH)
 	}
 }
@@ -373,7 +371,7 @@ func (g *RoomGraph) RandCell(mark int) int {
 		return -1
 	}
 
-	idx := rand.Intn(len(cells))
+	idx := rng.Intn(len(cells))
 	return cells[idx]
 }
 
@@ -393,7 +391,7 @@ func (g *RoomGraph) RandNeighbour(cell int, mark int) int {
 		return -1
 	}
 
-	idx := rand.Intn(len(nbList))
+	idx := rng.Intn(len(nbList))
 	return nbList[idx]
 }
 
@@ -426,8 +424,8 @@ func (r Room) TopLeft() Coord {
 
 // Returns a random point within the room ensuring it's not on a wall
 func (r Room) RandPoint() Coord {
-	x := r.X + rand.Intn(r.W-2) + 1
-	y := r.Y + rand.Intn(r.H-2) + 1
+	x := r.X + rng.Intn(r.W-2) + 1
+	y := r.Y + rng.Intn(r.H-2) + 1
 	return Coord{x, y}
 }
 
@@ -1,9 +1,6 @@
 package main
 
-import (
-	"fmt"
-	"math/rand"
-)
+import "fmt"
 
 type Item interface {
 	Rune() rune
@@ -30,7 +27,27 @@ type Equipable interface {
 
 	Equip(*Player, *MessageLog) bool
 	Unequip(*Player, *MessageLog) bool
-	//Identify()
+	IsIdentified() bool
+	Identify()
+	Practice() bool
+}
+
+// Items whose kind can be named by the player until they are identified
+type Callable interface {
+	Item
+
+	IsIdentified() bool
+	Call(string)
+}
+
+// Items of the same kind that can be merged into a single inventory slot
+type Stackable interface {
+	Item
+
+	Count() int
+	AdjustCount(amt int)
+	StacksWith(Item) bool
+	Split(qty int) Item
 }
 
 // -----------------------------------------------------------------------
@@ -51,7 +68,7 @@ func (list *ItemList) Clear() {
 // Stick    5    100
 
 func randItem() Item {
-	roll := rand.Intn(100) + 1
+	roll := rng.Intn(100) + 1
 	//debug.Add("rand item: roll=%d", roll)
 	switch {
 	case roll <= 27:
@@ -59,7 +76,7 @@ func randItem() Item {
 	case roll <= 54:
 		return randPotion()
 	case roll <= 72:
-		return newFood("ration")
+		return randFood()
 	case roll <= 81:
 		return randWeapon()
 	case roll <= 90:
@@ -74,6 +91,24 @@ func randItem() Item {
 
 }
 
+// The kind of item each rune on the map represents
+var ItemClassNames = map[rune]string{
+	'*': "gold",
+	'%': "food",
+	'!': "potion",
+	')': "weapon",
+	']': "armor",
+}
+
+// The display style each class of item is drawn with
+var ItemClassStyles = map[rune]string{
+	'*': "yellow",
+	'%': "brown",
+	'!': "magenta",
+	')': "cyan",
+	']': "cyan",
+}
+
 // === GOLD ==============================================================
 type Gold struct {
 	qty int
@@ -104,7 +139,7 @@ func newGold(qty int) *Gold {
 }
 
 func randGoldAmt(depth int) int {
-	return rand.Intn(50+10*depth) + 2
+	return rng.Intn(50+10*depth) + 2
 }
 
 // === EFFECTS ===========================================================
@@ -123,6 +158,8 @@ const (
 	E_Paralyze
 	E_Haste
 	E_Truesight
+	E_Slow
+	E_Sleep
 )
 
 func doEffect(effect int, gs *GameState) {
@@ -135,38 +172,98 @@ func doEffect(effect int, gs *GameState) {
 		//do nothing
 	case E_Healing:
 		gs.player.AdjustHP(gs.player.Level * 3)
-		gs.player.SetTimer("blind", 0)
-		gs.player.SetTimer("confusion", 0)
+		gs.player.CureStatus(StatusBlind, gs.messages)
+		gs.player.CureStatus(StatusConfused, gs.messages)
 	case E_ExtraHealing:
 		gs.player.AdjustHP(gs.player.Level * 5)
-		gs.player.SetTimer("blind", 0)
-		gs.player.SetTimer("confusion", 0)
+		gs.player.CureStatus(StatusBlind, gs.messages)
+		gs.player.CureStatus(StatusConfused, gs.messages)
 	case E_Strength:
 		gs.player.Str += 1
 		gs.player.maxStr += 1
 	case E_Poison:
-		gs.player.Str -= rand.Intn(3) + 1
+		if gs.player.Save(VsPoison) {
+			gs.messages.Add(MsgStatus, "You feel momentarily nauseous, but it passes.")
+		} else {
+			gs.player.Str -= rng.Intn(3) + 1
+		}
 	case E_Restore:
 		gs.player.Str = gs.player.maxStr
 	case E_Blindness:
-		gs.player.SetTimer("blind", 850)
+		gs.player.ApplyStatus(StatusBlind, 850, gs.messages)
 	case E_Confusion:
-		gs.player.SetTimer("confused", 20+rand.Intn(8))
+		gs.player.ApplyStatus(StatusConfused, 20+rng.Intn(8), gs.messages)
 	case E_DetMonsters:
-		gs.player.SetTimer("detMonsters", 850)
+		gs.player.ApplyStatus(StatusDetMonsters, 850, gs.messages)
 	case E_DetMagic:
-		gs.player.SetTimer("detMagic", 850)
+		gs.player.ApplyStatus(StatusDetMagic, 850, gs.messages)
 	case E_LevelUp:
 		gs.player.XP = XPTable[gs.player.Level]
 	case E_Paralyze:
-		gs.player.SetTimer("paralyzed", 3)
+		gs.player.ApplyStatus(StatusParalyzed, 3, gs.messages)
 	case E_Haste:
-		// if already hasted, faint for 0-7 turns
-		gs.player.SetTimer("haste", rand.Intn(5)+10)
+		// if already hasted, faint for 0-7 turns (see StackOverdose)
+		gs.player.ApplyStatus(StatusHaste, rng.Intn(5)+10, gs.messages)
+	case E_Slow:
+		gs.player.ApplyStatus(StatusSlow, rng.Intn(5)+10, gs.messages)
 	case E_Truesight:
-		gs.player.SetTimer("truesight", 850)
-		gs.player.SetTimer("blind", 0)
+		gs.player.ApplyStatus(StatusTruesight, 850, gs.messages)
+	default:
+		gs.messages.Add(MsgSystem, "This effect (%d) has not been implemented.", effect)
+	}
+}
+
+// Applies an effect to a monster (e.g. from a thrown potion, scroll or wand)
+func doMonsterEffect(effect int, m *Monster, gs *GameState) {
+	if effect == -1 {
+		panic("Unkown effect id")
+	}
+
+	// Harmful magic can be resisted
+	switch effect {
+	case E_Poison, E_Blindness, E_Confusion, E_Paralyze, E_Sleep, E_Slow:
+		which := VsMagic
+		if effect == E_Poison {
+			which = VsPoison
+		}
+		if m.Save(which) {
+			gs.messages.Add(MsgCombat, "The %v resists.", m)
+			return
+		}
+	}
+
+	switch effect {
+	case E_Nothing, E_DetMagic, E_DetMonsters, E_Truesight, E_Restore, E_Strength:
+		//do nothing
+	case E_Healing:
+		m.AdjustHP(m.Level * 3)
+		m.CureStatus(StatusBlind, gs.messages)
+		m.CureStatus(StatusConfused, gs.messages)
+	case E_ExtraHealing:
+		m.AdjustHP(m.Level * 5)
+		m.CureStatus(StatusBlind, gs.messages)
+		m.CureStatus(StatusConfused, gs.messages)
+	case E_Poison:
+		m.AdjustHP(-(rng.Intn(3) + 1))
+	case E_Blindness:
+		m.ApplyStatus(StatusBlind, 850, gs.messages)
+		gs.messages.Add(MsgCombat, "The %v stumbles about blindly.", m)
+	case E_Confusion:
+		m.ApplyStatus(StatusConfused, 20+rng.Intn(8), gs.messages)
+		gs.messages.Add(MsgCombat, "The %v appears confused.", m)
+	case E_LevelUp:
+		m.Level++
+		m.HP += rng.Intn(8) + 1
+	case E_Paralyze, E_Sleep:
+		m.ApplyStatus(StatusAsleep, rng.Intn(5)+3, gs.messages)
+		gs.messages.Add(MsgCombat, "The %v falls asleep.", m)
+	case E_Haste:
+		m.ApplyStatus(StatusHaste, rng.Intn(5)+10, gs.messages)
+		gs.messages.Add(MsgCombat, "The %v speeds up.", m)
+	case E_Slow:
+		m.ApplyStatus(StatusSlow, rng.Intn(5)+10, gs.messages)
+		gs.messages.Add(MsgCombat, "The %v slows down.", m)
 	default:
-		gs.messages.Add("This effect (%d) has not been implemented.", effect)
+		gs.messages.Add(MsgSystem, "This effect (%d) has not been implemented.", effect)
 	}
 }
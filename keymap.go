@@ -0,0 +1,296 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+/*************************************************************************
+ * Keymap
+ *
+ * The key bindings are built up in layers: the defaults (the lookup tables
+ * in display.go), then an optional preset and finally the bindings from the
+ * user's keymap file.  Each layer maps command names to lists of keys, e.g.
+ *
+ *   {
+ *     "preset": "vi",
+ *     "bindings": {
+ *       "look":     ["l", "Ctrl-L"],
+ *       "run-west": ["Shift-Left"]
+ *     }
+ *   }
+ *
+ * In the default layout the digits move on the numeric keypad, so a count is
+ * given after the count key, e.g. "n20s" searches 20 times.  The vi and rogue
+ * presets don't need the digits for movement and bind them to count instead,
+ * so there a bare "20s" works.
+ *
+ * A key bound by a later layer replaces whatever it did before, though taking
+ * a key the preset gave to another command is reported.  Keys are
+ * either a single character, "space" or a tcell key name (e.g. "PgUp",
+ * "Ctrl-D"), optionally prefixed with "Shift-".
+ */
+
+var viMovement = map[string][]string{
+	"west":          {"h"},
+	"south":         {"j"},
+	"north":         {"k"},
+	"east":          {"l"},
+	"northwest":     {"y"},
+	"northeast":     {"u"},
+	"southwest":     {"b"},
+	"southeast":     {"n"},
+	"run-west":      {"H"},
+	"run-south":     {"J"},
+	"run-north":     {"K"},
+	"run-east":      {"L"},
+	"run-northwest": {"Y"},
+	"run-northeast": {"U"},
+	"run-southwest": {"B"},
+	"run-southeast": {"N"},
+	"count":         {"#", "1", "2", "3", "4", "5", "6", "7", "8", "9"},
+}
+
+var KeymapPresets = map[string][]map[string][]string{
+	"default": {},
+	"numpad": {{
+		// The keypad with num lock off
+		"northwest": {"Home"},
+		"northeast": {"PgUp"},
+		"southwest": {"End"},
+		"southeast": {"PgDn"},
+	}},
+	"vi": {viMovement, {
+		"call": {"\""},
+	}},
+	"rogue": {viMovement, {
+		"consume":  {"q", "e", "r"},
+		"equip":    {"w", "W", "P", "T", "R"},
+		"call":     {"c"},
+		"messages": {"Ctrl-P"},
+		"debug4":   {"Ctrl-O"},
+	}},
+}
+
+type KeymapFile struct {
+	Preset   string              `json:"preset"`
+	Bindings map[string][]string `json:"bindings"`
+}
+
+// -----------------------------------------------------------------------
+// Returns where the named file is kept in the user's config directory
+func configPath(name string) string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, "gorogue", name)
+}
+
+// -----------------------------------------------------------------------
+// Loads the keymap file (if there is one) on top of the default bindings.
+// Any problems are returned rather than being fatal, the offending bindings
+// are simply skipped.
+func LoadKeymap(path string) []error {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return []error{err}
+	}
+
+	var file KeymapFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return []error{fmt.Errorf("%s: %w", path, err)}
+	}
+
+	var errs []error
+	if file.Preset != "" {
+		preset, ok := KeymapPresets[file.Preset]
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown preset %q", file.Preset))
+		}
+		for _, layer := range preset {
+			errs = append(errs, applyBindings(layer)...)
+		}
+		errs = append(errs, presetConflicts(file.Preset, file.Bindings)...)
+	}
+	errs = append(errs, applyBindings(file.Bindings)...)
+	return append(errs, checkUnbound()...)
+}
+
+// -----------------------------------------------------------------------
+// Reports keys the preset gave to one command that the bindings give to
+// another.  The bindings still win, but the other command loses the key.
+func presetConflicts(preset string, bindings map[string][]string) []error {
+	presetCmd := make(map[string]string)
+	for _, layer := range KeymapPresets[preset] {
+		for name, keys := range layer {
+			for _, str := range keys {
+				if k, err := parseKey(str); err == nil {
+					presetCmd[k.String()] = name
+				}
+			}
+		}
+	}
+
+	var errs []error
+	for name, keys := range bindings {
+		for _, str := range keys {
+			k, err := parseKey(str)
+			if err != nil {
+				continue // reported by applyBindings
+			}
+			if other, ok := presetCmd[k.String()]; ok && other != name {
+				errs = append(errs, fmt.Errorf("%s is bound to %s by the %s preset, now %s", k, other, preset, name))
+			}
+		}
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errs
+}
+
+// -----------------------------------------------------------------------
+// Binds each of the keys to its command, reporting keys that can't be
+// parsed, unknown commands and keys given to more than one command.
+func applyBindings(bindings map[string][]string) []error {
+	var errs []error
+
+	// Sort the commands so errors are reported consistently
+	names := make([]string, 0, len(bindings))
+	for name := range bindings {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	boundTo := make(map[string]string)
+	for _, name := range names {
+		cmd, ok := cmdByName(name)
+		if !ok {
+			errs = append(errs, fmt.Errorf("unknown command %q", name))
+			continue
+		}
+		for _, str := range bindings[name] {
+			k, err := parseKey(str)
+			if err != nil {
+				errs = append(errs, err)
+				continue
+			}
+			if other, ok := boundTo[k.String()]; ok {
+				errs = append(errs, fmt.Errorf("%s is bound to both %s and %s", k, other, name))
+				continue
+			}
+			boundTo[k.String()] = name
+			k.bind(cmd)
+		}
+	}
+	return errs
+}
+
+// -----------------------------------------------------------------------
+// Reports commands that no longer have any key (e.g. their only key was
+// given to something else).  Debug commands may be left without a key.
+func checkUnbound() []error {
+	bound := ActiveBindings()
+	var errs []error
+	for cmd, c := range CommandLib {
+		if len(bound[cmd]) == 0 && !c.debug {
+			errs = append(errs, fmt.Errorf("%s has no key", c.name))
+		}
+	}
+	sort.Slice(errs, func(i, j int) bool { return errs[i].Error() < errs[j].Error() })
+	return errs
+}
+
+func cmdByName(name string) (GameCommand, bool) {
+	for cmd, c := range CommandLib {
+		if c.name == name {
+			return cmd, true
+		}
+	}
+	return CmdNop, false
+}
+
+// -----------------------------------------------------------------------
+// A single key, as found in one of the lookup tables
+type KeyBinding struct {
+	key   tcell.Key
+	ch    rune // only for tcell.KeyRune
+	shift bool
+}
+
+func parseKey(str string) (KeyBinding, error) {
+	if strings.EqualFold(str, "space") {
+		return KeyBinding{key: tcell.KeyRune, ch: ' '}, nil
+	}
+	if r := []rune(str); len(r) == 1 {
+		return KeyBinding{key: tcell.KeyRune, ch: r[0]}, nil
+	}
+
+	k := KeyBinding{}
+	name := str
+	if len(name) > 6 && strings.EqualFold(name[:6], "shift-") {
+		k.shift = true
+		name = name[6:]
+	}
+	for key, n := range tcell.KeyNames {
+		if strings.EqualFold(n, name) {
+			k.key = key
+			return k, nil
+		}
+	}
+	return k, fmt.Errorf("unknown key %q", str)
+}
+
+func (k KeyBinding) bind(cmd GameCommand) {
+	switch {
+	case k.key == tcell.KeyRune:
+		RuneCmdLookup[k.ch] = cmd
+	case k.shift:
+		ShiftKeyCmdLookup[k.key] = cmd
+	default:
+		KeyCmdLookup[k.key] = cmd
+	}
+}
+
+func (k KeyBinding) String() string {
+	switch {
+	case k.key == tcell.KeyRune && k.ch == ' ':
+		return "space"
+	case k.key == tcell.KeyRune:
+		return string(k.ch)
+	case k.shift:
+		return "Shift-" + tcell.KeyNames[k.key]
+	default:
+		return tcell.KeyNames[k.key]
+	}
+}
+
+// -----------------------------------------------------------------------
+// Returns the keys currently bound to each command, sorted by name
+func ActiveBindings() map[GameCommand][]KeyBinding {
+	bound := make(map[GameCommand][]KeyBinding)
+	for ch, cmd := range RuneCmdLookup {
+		bound[cmd] = append(bound[cmd], KeyBinding{key: tcell.KeyRune, ch: ch})
+	}
+	for key, cmd := range KeyCmdLookup {
+		bound[cmd] = append(bound[cmd], KeyBinding{key: key})
+	}
+	for key, cmd := range ShiftKeyCmdLookup {
+		bound[cmd] = append(bound[cmd], KeyBinding{key: key, shift: true})
+	}
+	for _, keys := range bound {
+		sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+	}
+	return bound
+}
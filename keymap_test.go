@@ -0,0 +1,179 @@
+package main
+
+import (
+	"maps"
+	"slices"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// Restores the default key bindings once the test is done
+func saveBindings(t *testing.T) {
+	keys := maps.Clone(KeyCmdLookup)
+	shift := maps.Clone(ShiftKeyCmdLookup)
+	runes := maps.Clone(RuneCmdLookup)
+	t.Cleanup(func() {
+		KeyCmdLookup, ShiftKeyCmdLookup, RuneCmdLookup = keys, shift, runes
+	})
+}
+
+func TestParseKey(t *testing.T) {
+	tests := []struct {
+		str     string
+		want    KeyBinding
+		wantErr bool
+	}{
+		{"k", KeyBinding{key: tcell.KeyRune, ch: 'k'}, false},
+		{"K", KeyBinding{key: tcell.KeyRune, ch: 'K'}, false},
+		{"space", KeyBinding{key: tcell.KeyRune, ch: ' '}, false},
+		{"PgUp", KeyBinding{key: tcell.KeyPgUp}, false},
+		{"pgup", KeyBinding{key: tcell.KeyPgUp}, false},
+		{"Ctrl-D", KeyBinding{key: tcell.KeyCtrlD}, false},
+		{"Shift-Left", KeyBinding{key: tcell.KeyLeft, shift: true}, false},
+		{"Shift-", KeyBinding{}, true},
+		{"Hyper-X", KeyBinding{}, true},
+		{"", KeyBinding{}, true},
+	}
+	for _, tt := range tests {
+		got, err := parseKey(tt.str)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseKey(%q) error = %v, wantErr %v", tt.str, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("parseKey(%q) = %+v, want %+v", tt.str, got, tt.want)
+		}
+		if !tt.wantErr && got.String() == "" {
+			t.Errorf("parseKey(%q).String() is empty", tt.str)
+		}
+	}
+}
+
+func TestApplyBindings(t *testing.T) {
+	tests := []struct {
+		name     string
+		bindings map[string][]string
+		errs     int
+		check    map[string]GameCommand // key -> command afterwards
+	}{
+		{
+			name:     "rebind",
+			bindings: map[string][]string{"look": {"l", "Shift-Home"}},
+			check:    map[string]GameCommand{"l": CmdLook, ";": CmdLook, "Shift-Home": CmdLook},
+		},
+		{
+			name:     "replaces old binding",
+			bindings: map[string][]string{"look": {"i"}},
+			check:    map[string]GameCommand{"i": CmdLook},
+		},
+		{
+			name:     "conflict",
+			bindings: map[string][]string{"explore": {"z"}, "look": {"z"}},
+			errs:     1,
+			check:    map[string]GameCommand{"z": CmdExplore},
+		},
+		{
+			name:     "unknown command",
+			bindings: map[string][]string{"fly": {"z"}},
+			errs:     1,
+		},
+		{
+			name:     "unknown key",
+			bindings: map[string][]string{"look": {"Hyper-X", "z"}},
+			errs:     1,
+			check:    map[string]GameCommand{"z": CmdLook},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			saveBindings(t)
+			if errs := applyBindings(tt.bindings); len(errs) != tt.errs {
+				t.Errorf("got errors %v, want %d", errs, tt.errs)
+			}
+			for str, want := range tt.check {
+				k, _ := parseKey(str)
+				if got := lookupBinding(k); got != want {
+					t.Errorf("%s is bound to %v, want %v", str, got, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPresets(t *testing.T) {
+	for name, layers := range KeymapPresets {
+		t.Run(name, func(t *testing.T) {
+			saveBindings(t)
+			for _, layer := range layers {
+				if errs := applyBindings(layer); len(errs) > 0 {
+					t.Errorf("errors in preset: %v", errs)
+				}
+			}
+			if errs := checkUnbound(); len(errs) > 0 {
+				t.Errorf("preset leaves commands unbound: %v", errs)
+			}
+		})
+	}
+}
+
+func lookupBinding(k KeyBinding) GameCommand {
+	switch {
+	case k.key == tcell.KeyRune:
+		return RuneCmdLookup[k.ch]
+	case k.shift:
+		return ShiftKeyCmdLookup[k.key]
+	default:
+		return KeyCmdLookup[k.key]
+	}
+}
+
+func TestCountExample(t *testing.T) {
+	tests := []struct {
+		preset string
+		want   string
+	}{
+		{"default", "n20s"},
+		{"numpad", "n20s"},
+		{"vi", "20s"},
+		{"rogue", "20s"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.preset, func(t *testing.T) {
+			saveBindings(t)
+			for _, layer := range KeymapPresets[tt.preset] {
+				applyBindings(layer)
+			}
+			if got := countExample(ActiveBindings()); got != tt.want {
+				t.Errorf("countExample() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPresetConflicts(t *testing.T) {
+	tests := []struct {
+		name     string
+		preset   string
+		bindings map[string][]string
+		want     []string
+	}{
+		{"no conflict", "vi", map[string][]string{"look": {"Ctrl-L"}}, nil},
+		{"same command", "vi", map[string][]string{"west": {"h", "Left"}}, nil},
+		{"takes a preset key", "vi", map[string][]string{"look": {"l"}},
+			[]string{"l is bound to east by the vi preset, now look"}},
+		{"default key", "vi", map[string][]string{"look": {"i"}}, nil},
+		{"numpad key", "numpad", map[string][]string{"look": {"Home"}},
+			[]string{"Home is bound to northwest by the numpad preset, now look"}},
+		{"bad key", "vi", map[string][]string{"look": {"Hyper-X"}}, nil},
+	}
+	for _, tt := range tests {
+		var got []string
+		for _, err := range presetConflicts(tt.preset, tt.bindings) {
+			got = append(got, err.Error())
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: presetConflicts() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,9 +1,18 @@
 package main
 
-import "fmt"
+import (
+	"flag"
+	"fmt"
+	"time"
+)
 
 var debug DebugMessageLog
 
+// Enabled with -wizard, lists the debug commands on the help screen
+var wizardMode bool
+
+var playerName string
+
 var debugFlag = map[string]bool{
 	"main":     false,
 	"generate": false,
@@ -11,6 +20,15 @@ var debugFlag = map[string]bool{
 	"path":     false,
 }
 
+// Item classes (by rune) that are picked up automatically when walked over
+var autoPickup = map[rune]bool{
+	'*': true, // gold
+	'%': true, // food
+	'!': true, // potions
+	')': true, // weapons
+	']': true, // armor
+}
+
 // For testing
 var path1 Path
 var path2 Path
@@ -21,7 +39,10 @@ const (
 	NutritionTime = 1300
 	HungerLimit   = 300
 	WeakLimit     = 150
+	FaintLimit    = 0
+	StarveLimit   = -850
 	SpawnFood     = 3 // Guarantee food spawns every 3 levels
+	MaxPack       = 23
 )
 
 type GameCommand int
@@ -48,6 +69,30 @@ const (
 	CmdDown
 	CmdConsume
 	CmdEquip
+	CmdThrow
+	CmdDrop
+	CmdPickup
+	CmdAutoPickup
+	CmdCall
+	CmdDiscoveries
+	CmdSearch
+	CmdRun // prefix, followed by a direction
+	CmdCount
+	CmdRunNorth
+	CmdRunNorthEast
+	CmdRunEast
+	CmdRunSouthEast
+	CmdRunSouth
+	CmdRunSouthWest
+	CmdRunWest
+	CmdRunNorthWest
+	CmdTravel
+	CmdExplore
+	CmdLook
+	CmdClick // mouse click on the map, see Display.clicked
+	CmdHelp
+	CmdMonochrome
+	CmdCharacter
 
 	CmdTick
 	CmdGenerate // for testing
@@ -55,8 +100,84 @@ const (
 	CmdInventory
 )
 
+// The movement commands and the direction each one represents
+var CmdDirections = map[GameCommand]Coord{
+	CmdNorth:     {0, -1},
+	CmdNorthEast: {1, -1},
+	CmdEast:      {1, 0},
+	CmdSouthEast: {1, 1},
+	CmdSouth:     {0, 1},
+	CmdSouthWest: {-1, 1},
+	CmdWest:      {-1, 0},
+	CmdNorthWest: {-1, -1},
+}
+
+// The shortcuts to start running in a given direction
+var RunCmdDirections = map[GameCommand]Coord{
+	CmdRunNorth:     {0, -1},
+	CmdRunNorthEast: {1, -1},
+	CmdRunEast:      {1, 0},
+	CmdRunSouthEast: {1, 1},
+	CmdRunSouth:     {0, 1},
+	CmdRunSouthWest: {-1, 1},
+	CmdRunWest:      {-1, 0},
+	CmdRunNorthWest: {-1, -1},
+}
+
+// Returns the movement command for the given direction
+func directionCmd(dir Coord) GameCommand {
+	for cmd, d := range CmdDirections {
+		if d == dir {
+			return cmd
+		}
+	}
+	return CmdNop
+}
+
+// -----------------------------------------------------------------------
+// A command that is repeated automatically, either a set number of times,
+// along a path when travelling or, when running or exploring, until something
+// interesting happens.
+type Repeat struct {
+	cmd       GameCommand
+	count     int
+	running   bool
+	path      []Coord
+	exploring bool
+}
+
+func (r *Repeat) Active() bool {
+	return r.running || r.count > 0 || len(r.path) > 0 || r.exploring
+}
+
+func (r *Repeat) Stop() {
+	*r = Repeat{}
+}
+
+// Sets up travelling along a path to the destination, returning the command
+// for the first step.
+func travelTo(gs *GameState, dest Coord) (GameCommand, Repeat) {
+	path := findPathBFS(gs.dungeon, gs.player.Pos(), dest, true)
+	if len(path.steps) == 0 {
+		if dest != gs.player.Pos() {
+			gs.messages.Add(MsgSystem, "You don't know how to get there.")
+		}
+		return CmdNop, Repeat{}
+	}
+	cmd := directionCmd(path.steps[0].Diff(gs.player.Pos()))
+	return cmd, Repeat{path: path.steps[1:]}
+}
+
 // -----------------------------------------------------------------------
 func main() {
+	flag.BoolVar(&wizardMode, "wizard", false, "list debug commands on the help screen")
+	flag.StringVar(&playerName, "name", "", "name of your character")
+	seed := flag.Int64("seed", 0, "random seed for the dungeon (0 picks one)")
+	morgueDir := flag.String("morgue", configPath("morgue"), "directory for character dumps")
+	flag.Parse()
+	if *seed == 0 {
+		*seed = time.Now().UnixNano()
+	}
 
 	// Initialization
 	var display Display
@@ -65,10 +186,22 @@ func main() {
 
 	// Set up the initial game state
 	var state GameState
-	state.Init()
+	state.Init(*seed)
+	if playerName != "" {
+		state.player.Name = playerName
+	}
+	for _, err := range LoadKeymap(configPath("keymap.json")) {
+		state.messages.Add(MsgSystem, "Keymap: %v", err)
+	}
+	for _, err := range display.LoadTheme(configPath("theme.json")) {
+		state.messages.Add(MsgSystem, "Theme: %v", err)
+	}
 
 	var doUpdate bool   // If game time has passed this iteration
 	var cmd GameCommand // Determined from user's input
+	var repeat Repeat   // Running or a command given with a count
+	var morguePath string
+	var morgueErr error
 
 	// Main Game Loop
 	done := false
@@ -76,7 +209,7 @@ func main() {
 
 		// DEBUG: For testing pathfinding
 		dest := state.dungeon.rooms[RoomID].Center()
-		path1 = findPathBFS(state.dungeon, state.player.Pos(), dest)
+		path1 = findPathBFS(state.dungeon, state.player.Pos(), dest, false)
 		path2 = state.dmap.PathFrom(dest)
 
 		// Draw the game world and refresh the display
@@ -85,8 +218,58 @@ func main() {
 		drawDebug(&display, &state)
 		display.Show()
 
-		// Get user's command (this blocks until we get a key event)
-		cmd = display.GetCommand(state.messages)
+		// Get user's command (this blocks until we get a key event) unless
+		// we're in the middle of repeating one
+		if len(repeat.path) > 0 {
+			cmd = directionCmd(repeat.path[0].Diff(state.player.Pos()))
+			repeat.path = repeat.path[1:]
+		} else if repeat.exploring {
+			cmd = CmdExplore
+		} else if repeat.Active() {
+			cmd = repeat.cmd
+			if !repeat.running {
+				repeat.count--
+			}
+		} else {
+			cmd = display.GetCommand(&state)
+		}
+
+		// Prefixes that set up a repeated command
+		switch cmd {
+		case CmdRun:
+			cmd = CmdNop
+			if dir, ok := display.PromptDirection("Run in what direction?"); ok {
+				cmd = directionCmd(dir)
+				repeat = Repeat{cmd: cmd, running: true}
+			}
+		case CmdRunNorth, CmdRunNorthEast, CmdRunEast, CmdRunSouthEast,
+			CmdRunSouth, CmdRunSouthWest, CmdRunWest, CmdRunNorthWest:
+			cmd = directionCmd(RunCmdDirections[cmd])
+			repeat = Repeat{cmd: cmd, running: true}
+		case CmdTravel:
+			cmd = CmdNop
+			dest, ok := display.SelectPosition("Travel to where?", state.player.Pos(), state.RememberedPositions, nil)
+			if ok {
+				cmd, repeat = travelTo(&state, dest)
+			}
+		case CmdClick:
+			cmd, repeat = travelTo(&state, display.clicked)
+		case CmdExplore:
+			repeat = Repeat{exploring: true}
+			cmd = CmdNop
+			if dir, ok := state.ExploreStep(); ok {
+				cmd = directionCmd(dir)
+			} else {
+				repeat.Stop()
+			}
+		case CmdCount:
+			count, next := display.PromptCount()
+			cmd = next
+			if count > 1 {
+				repeat = Repeat{cmd: cmd, count: count - 1}
+			}
+		}
+		seen := state.VisibleMonsters()
 
 		// Handle user's command
 		doUpdate = false
@@ -98,32 +281,51 @@ func main() {
 		case CmdTick:
 			// Do nothing.  Used to redraw, clear recent messages, etc.
 		case CmdMessages:
-			display.DrawMessageHistory(state.messages)
-			display.WaitForKeypress()
+			display.MessageHistoryScreen(state.messages)
 		case CmdInventory:
 			display.InventoryScreen(state.player)
+			display.BrowseInventory(state.player)
+		case CmdHelp:
+			display.HelpScreen()
+		case CmdCharacter:
+			display.CharacterScreen(state.player)
 			display.WaitForKeypress()
+		case CmdMonochrome:
+			display.monochrome = !display.monochrome
+			state.messages.Add(MsgSystem, "Colours are now %v.", onOff(!display.monochrome))
+		case CmdLook:
+			pos, ok := display.SelectPosition("Look at what?", state.player.Pos(), state.RememberedPositions, state.Describe)
+			if ok {
+				state.messages.Add(MsgSystem, "%s", state.Describe(pos))
+			}
+		case CmdDiscoveries:
+			display.DiscoveriesScreen()
+			display.WaitForKeypress()
+		case CmdCall:
+			idx := display.PromptInventory("Call what?", state.player)
+			if idx != -1 {
+				switch item := state.player.inventory[idx].(type) {
+				case Callable:
+					if item.IsIdentified() {
+						state.messages.Add(MsgItem, "You already know what that is.")
+					} else {
+						name, ok := display.PromptString("What do you want to call it?")
+						if ok {
+							item.Call(name)
+						}
+					}
+				default:
+					state.messages.Add(MsgItem, "You can't call that anything.")
+				}
+			}
 		case CmdQuit:
 			done = true
 			state.player.killedBy = "quitting"
 
 		// Commands that do increment time
-		case CmdNorth:
-			doUpdate = state.MoveActor(state.player, Coord{0, -1})
-		case CmdNorthEast:
-			doUpdate = state.MoveActor(state.player, Coord{1, -1})
-		case CmdEast:
-			doUpdate = state.MoveActor(state.player, Coord{1, 0})
-		case CmdSouthEast:
-			doUpdate = state.MoveActor(state.player, Coord{1, 1})
-		case CmdSouth:
-			doUpdate = state.MoveActor(state.player, Coord{0, 1})
-		case CmdSouthWest:
-			doUpdate = state.MoveActor(state.player, Coord{-1, 1})
-		case CmdWest:
-			doUpdate = state.MoveActor(state.player, Coord{-1, 0})
-		case CmdNorthWest:
-			doUpdate = state.MoveActor(state.player, Coord{-1, -1})
+		case CmdNorth, CmdNorthEast, CmdEast, CmdSouthEast,
+			CmdSouth, CmdSouthWest, CmdWest, CmdNorthWest:
+			doUpdate = state.MoveActor(state.player, CmdDirections[cmd])
 		case CmdDown:
 			doUpdate = state.GoDownstairs()
 		case CmdUp:
@@ -131,10 +333,13 @@ func main() {
 		case CmdWait:
 			doUpdate = true
 			//messages.Add("You rest for a moment.")
+		case CmdSearch:
+			// Nothing to find yet (e.g. hidden doors, traps) but time passes
+			doUpdate = true
 
 		case CmdConsume:
 			if state.player.IsParalyzed() {
-				state.messages.Add("You cannot consume anything while paralyzed.")
+				state.messages.Add(MsgStatus, "You cannot consume anything while paralyzed.")
 			} else {
 				idx := display.PromptInventory("Consume what?", state.player)
 				if idx != -1 {
@@ -142,16 +347,16 @@ func main() {
 					switch item.(type) {
 					case Consumable:
 						doUpdate = item.(Consumable).Consume(&state)
-						state.player.RemoveItem(idx)
+						state.player.RemoveItem(idx, 1)
 					default:
-						state.messages.Add("You cannot consume that item.")
+						state.messages.Add(MsgItem, "You cannot consume that item.")
 					}
 				}
 			}
 
 		case CmdEquip:
 			if state.player.IsParalyzed() {
-				state.messages.Add("You cannot equip anything while paralyzed.")
+				state.messages.Add(MsgStatus, "You cannot equip anything while paralyzed.")
 			} else {
 				idx := display.PromptInventory("Equip or unequip what?", state.player)
 				if idx != -1 {
@@ -160,11 +365,50 @@ func main() {
 					case Equipable:
 						doUpdate = item.(Equipable).Equip(state.player, state.messages)
 					default:
-						state.messages.Add("You cannot equip that item.")
+						state.messages.Add(MsgItem, "You cannot equip that item.")
 					}
 				}
 			}
 
+		case CmdThrow:
+			if state.player.IsParalyzed() {
+				state.messages.Add(MsgStatus, "You cannot throw anything while paralyzed.")
+			} else {
+				idx := display.PromptInventory("Throw what?", state.player)
+				if idx != -1 {
+					dir, ok := display.PromptDirection("In what direction?")
+					if ok {
+						doUpdate = state.ThrowItem(idx, dir)
+					}
+				}
+			}
+
+		case CmdDrop:
+			if state.player.IsParalyzed() {
+				state.messages.Add(MsgStatus, "You cannot drop anything while paralyzed.")
+			} else {
+				idx := display.PromptInventory("Drop what?", state.player)
+				if idx != -1 {
+					doUpdate = state.DropItem(idx)
+				}
+			}
+
+		case CmdPickup:
+			if state.player.IsParalyzed() {
+				state.messages.Add(MsgStatus, "You cannot pick up anything while paralyzed.")
+			} else {
+				doUpdate = state.PickupItem()
+			}
+
+		case CmdAutoPickup:
+			ch := display.Prompt("Toggle auto-pickup for which item class? (e.g. !, ESC to cancel)")
+			if _, ok := autoPickup[ch]; ok {
+				autoPickup[ch] = !autoPickup[ch]
+				state.messages.Add(MsgSystem, "Auto-pickup for '%c' is now %v.", ch, onOff(autoPickup[ch]))
+			} else if ch != -1 && ch != 0 { // 0 for keys that aren't characters
+				state.messages.Add(MsgSystem, "There is no item class '%c'.", ch)
+			}
+
 		// Extra debugging and testing stuff
 		case CmdDebug1:
 			debugFlag["main"] = !debugFlag["main"]
@@ -183,7 +427,7 @@ func main() {
 			debug.Clear()
 			GenerateTestLevel(&state)
 		default:
-			state.messages.Add("Unknown command.")
+			state.messages.Add(MsgSystem, "Unknown command.")
 		}
 
 		// Check for objects on the ground
@@ -194,18 +438,32 @@ func main() {
 		state.UpdatePlayerFOV()
 
 		if doUpdate {
-			state.PruneMonsters()
-			state.player.Update(state.messages)
-			if !state.IsBonusMove() {
-				state.MonstersAct()
-				state.WanderingMonsters()
+			state.EndPlayerTurn()
+		}
+
+		// Stop repeating if anything happened that the player should know about
+		if repeat.Active() {
+			if !doUpdate || state.messages.HasUnread() || state.VisibleMonsters() > seen {
+				repeat.Stop()
+			} else if len(repeat.path) > 0 && repeat.path[0].Distance(state.player.Pos()) != 1 {
+				repeat.Stop() // knocked off course (e.g. confused)
+			} else if repeat.exploring && state.player.HP < state.player.maxHP/3 {
+				state.messages.Add(MsgDanger, "You stop exploring, your health is low.")
+				repeat.Stop()
+			} else if repeat.running {
+				dir, ok := state.RunDirection(CmdDirections[cmd])
+				if ok {
+					repeat.cmd = directionCmd(dir)
+				} else {
+					repeat.Stop()
+				}
 			}
 		}
 
 		// check for game over
 		if state.player.HP <= 0 {
 			done = true
-			state.messages.Add("You have died (press SPACE to continue).")
+			state.messages.Add(MsgDanger, "You have died (press SPACE to continue).")
 			display.Clear()
 			draw(&display, &state)
 			display.Show()
@@ -218,6 +476,8 @@ func main() {
 				switch item.(type) {
 				case Consumable:
 					item.(Consumable).Identify()
+				case Equipable:
+					item.(Equipable).Identify()
 				}
 			}
 			display.Clear()
@@ -228,10 +488,17 @@ func main() {
 			display.ListInventory(state.player, len(msg), true)
 			display.WaitForKeypress()
 
+			morguePath, morgueErr = writeMorgue(&state, *morgueDir)
+
 			display.TombstoneScreen(&state)
 		}
 	}
 	display.Quit()
+	if morgueErr != nil {
+		fmt.Printf("Unable to write character dump: %v\n", morgueErr)
+	} else if morguePath != "" {
+		fmt.Printf("Character dump written to %s\n", morguePath)
+	}
 	fmt.Printf("Your final score: %d\n", state.player.Score())
 	fmt.Println("Thanks for playing!")
 }
@@ -257,14 +524,14 @@ func draw(display *Display, state *GameState) {
 	}
 
 	// monster detection should work even if blind
-	if state.player.Timer("detMonsters") > 0 {
+	if state.player.Status(StatusDetMonsters) > 0 {
 		for _, m := range *state.monsters {
 			display.DrawActor(m)
 		}
 	}
 
 	// if detect magic should work even if blind
-	if state.player.Timer("detMagic") > 0 {
+	if state.player.Status(StatusDetMagic) > 0 {
 		for pos, item := range state.items {
 			//if item.IsMagical() { //TODO
 			display.DrawItem(pos, item)
@@ -272,10 +539,14 @@ func draw(display *Display, state *GameState) {
 		}
 	}
 
-	display.DrawMessages(state.messages)
-	display.Print(0, 24, state.player.InfoString())
-
+	display.Print(0, StatusRow, state.player.InfoString())
+	if !debugFlag["main"] {
+		display.DrawSidePanel(state)
+	}
 	display.DrawPlayer(state.player)
+
+	// Last since it may wait at a --More-- prompt
+	display.DrawMessages(state.messages)
 }
 
 // -----------------------------------------------------------------------
@@ -301,6 +572,7 @@ func GenerateTestLevel(gs *GameState) {
 	gs.dungeon.Clear()
 	gs.monsters.Clear()
 	gs.items.Clear()
+	gs.seenItems.Clear()
 
 	p1 := gs.dungeon.CreateRoom(Coord{44, 6}, 13, 7)
 	p2 := gs.dungeon.CreateRoom(Coord{25, 15}, 11, 7)
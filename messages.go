@@ -5,29 +5,91 @@ import (
 	"strings"
 )
 
+// -----------------------------------------------------------------------
+type MsgCategory int
+
+const (
+	MsgSystem MsgCategory = iota
+	MsgCombat
+	MsgItem
+	MsgStatus
+	MsgDanger
+)
+
+var MsgCategoryNames = map[MsgCategory]string{
+	MsgSystem: "system",
+	MsgCombat: "combat",
+	MsgItem:   "item",
+	MsgStatus: "status",
+	MsgDanger: "danger",
+}
+
+// The display style each category is drawn with
+var MsgStyles = map[MsgCategory]string{
+	MsgSystem: "default",
+	MsgCombat: "orange",
+	MsgItem:   "bluegreen",
+	MsgStatus: "yellow",
+	MsgDanger: "red",
+}
+
+// -----------------------------------------------------------------------
+type Message struct {
+	text  string
+	cat   MsgCategory
+	turn  int // when it happened, see MessageLog.SetTurn
+	count int // number of times in a row it happened
+}
+
+func (m Message) String() string {
+	if m.count > 1 {
+		return fmt.Sprintf("%s x%d", m.text, m.count)
+	}
+	return m.text
+}
+
 // -----------------------------------------------------------------------
 type MessageLog struct {
-	messages []string
+	messages []Message
 	idx      int
+	turn     int
 }
 
-func (log *MessageLog) Add(format string, vals ...any) {
-	if format == "" {
+// Adds a message, or if it is the same as the last one just counts it again
+// (e.g. "You miss the bat. x3") and marks it as unread.
+func (log *MessageLog) Add(cat MsgCategory, format string, vals ...any) {
+	msg := fmt.Sprintf(format, vals...)
+	if msg == "" {
 		return
 	}
-	msg := fmt.Sprintf(format, vals...)
-	log.messages = append(log.messages, msg)
+	if n := len(log.messages); n > 0 && log.messages[n-1].text == msg {
+		last := &log.messages[n-1]
+		last.count++
+		last.turn = log.turn
+		log.idx = min(log.idx, n-1)
+		return
+	}
+	log.messages = append(log.messages, Message{msg, cat, log.turn, 1})
+}
+
+// Sets the turn number that new messages are tagged with
+func (log *MessageLog) SetTurn(turn int) {
+	log.turn = turn
 }
 
 func (log *MessageLog) Clear() {
 	log.messages = nil
 }
 
+func (log *MessageLog) Count() int {
+	return len(log.messages)
+}
+
 func (log *MessageLog) HasUnread() bool {
 	return log.idx < len(log.messages)
 }
 
-func (log *MessageLog) Last(n int) []string {
+func (log *MessageLog) Last(n int) []Message {
 	if n >= len(log.messages) {
 		return log.messages
 	} else {
@@ -35,14 +97,34 @@ func (log *MessageLog) Last(n int) []string {
 	}
 }
 
-func (log *MessageLog) LatestAsStr() string {
-	s := ""
-	if len(log.messages[log.idx:]) > 0 {
-		s = strings.Join(log.messages[log.idx:], " ")
+// Returns the unread messages
+func (log *MessageLog) Unread() []Message {
+	return log.messages[log.idx:]
+}
+
+// Returns only the messages in the given category
+func (log *MessageLog) Filter(cat MsgCategory) []Message {
+	var list []Message
+	for _, m := range log.messages {
+		if m.cat == cat {
+			list = append(list, m)
+		}
 	}
-	return s
+	return list
 }
 
 func (log *MessageLog) ClearUnread() {
 	log.idx = len(log.messages)
 }
+
+// Returns the index of the latest message before the given one that
+// contains the text (ignoring case), or -1 if there isn't one.
+func searchMessages(msgs []Message, text string, before int) int {
+	text = strings.ToLower(text)
+	for i := min(before, len(msgs)) - 1; i >= 0; i-- {
+		if strings.Contains(strings.ToLower(msgs[i].text), text) {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,174 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+// Returns the text of each line of wrapped messages
+func lineStrings(lines [][]Message) []string {
+	var strs []string
+	for _, line := range lines {
+		s := ""
+		for i, m := range line {
+			if i > 0 {
+				s += " "
+			}
+			s += m.String()
+		}
+		strs = append(strs, s)
+	}
+	return strs
+}
+
+func TestWrapMessages(t *testing.T) {
+	tests := []struct {
+		name  string
+		msgs  []string
+		width int
+		want  []string
+	}{
+		{"empty", nil, 20, nil},
+		{"one line", []string{"You hit.", "It dies."}, 20, []string{"You hit. It dies."}},
+		{"exact fit", []string{"1234567890", "123456789"}, 20, []string{"1234567890 123456789"}},
+		{"next line", []string{"You hit the bat.", "It dies."}, 20, []string{"You hit the bat.", "It dies."}},
+		{"long message", []string{"The quick brown fox jumps over the dog."}, 20,
+			[]string{"The quick brown fox", "jumps over the dog."}},
+		{"long after short", []string{"Ouch!", "The quick brown fox jumps over the dog."}, 20,
+			[]string{"Ouch! The quick", "brown fox jumps over", "the dog."}},
+	}
+	for _, tt := range tests {
+		var msgs []Message
+		for _, m := range tt.msgs {
+			msgs = append(msgs, Message{text: m, count: 1})
+		}
+		got := lineStrings(wrapMessages(msgs, tt.width))
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: wrapMessages() = %q, want %q", tt.name, got, tt.want)
+		}
+		for _, line := range got {
+			if len(line) > tt.width {
+				t.Errorf("%s: line %q is wider than %d", tt.name, line, tt.width)
+			}
+		}
+	}
+}
+
+// Split messages keep their category so each piece is drawn in its colour
+func TestWrapMessagesCategory(t *testing.T) {
+	msgs := []Message{{text: "The quick brown fox jumps over the dog.", cat: MsgDanger, count: 1}}
+	for _, line := range wrapMessages(msgs, 20) {
+		for _, m := range line {
+			if m.cat != MsgDanger {
+				t.Errorf("%q has category %v, want %v", m.text, m.cat, MsgDanger)
+			}
+		}
+	}
+}
+
+func TestMessageLogAdd(t *testing.T) {
+	tests := []struct {
+		name string
+		msgs []string
+		want []string
+	}{
+		{"different", []string{"You miss the bat.", "The bat bites you."},
+			[]string{"You miss the bat.", "The bat bites you."}},
+		{"repeated", []string{"You miss the bat.", "You miss the bat.", "You miss the bat."},
+			[]string{"You miss the bat. x3"}},
+		{"not in a row", []string{"You miss the bat.", "The bat bites you.", "You miss the bat."},
+			[]string{"You miss the bat.", "The bat bites you.", "You miss the bat."}},
+		{"empty ignored", []string{"You miss the bat.", ""}, []string{"You miss the bat."}},
+	}
+	for _, tt := range tests {
+		log := &MessageLog{}
+		for _, m := range tt.msgs {
+			log.Add(MsgCombat, "%s", m)
+		}
+		var got []string
+		for _, m := range log.messages {
+			got = append(got, m.String())
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: messages = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// A repeated message is shown again even if the first one was already read
+func TestMessageLogRepeatUnread(t *testing.T) {
+	log := &MessageLog{}
+	log.Add(MsgCombat, "You miss the bat.")
+	log.ClearUnread()
+	log.SetTurn(7)
+	log.Add(MsgCombat, "You miss the bat.")
+
+	unread := log.Unread()
+	if len(unread) != 1 || unread[0].String() != "You miss the bat. x2" || unread[0].turn != 7 {
+		t.Errorf("Unread() = %v, want the collapsed message on turn 7", unread)
+	}
+}
+
+func TestMessageLogFilter(t *testing.T) {
+	log := &MessageLog{}
+	log.Add(MsgSystem, "Welcome!")
+	log.Add(MsgCombat, "You hit the bat.")
+	log.Add(MsgItem, "You pick up a dart.")
+	log.Add(MsgCombat, "The bat dies.")
+
+	tests := []struct {
+		cat  MsgCategory
+		want int
+	}{
+		{MsgSystem, 1},
+		{MsgCombat, 2},
+		{MsgItem, 1},
+		{MsgDanger, 0},
+	}
+	for _, tt := range tests {
+		got := log.Filter(tt.cat)
+		if len(got) != tt.want {
+			t.Errorf("Filter(%s) has %d messages, want %d", MsgCategoryNames[tt.cat], len(got), tt.want)
+		}
+		for _, m := range got {
+			if m.cat != tt.cat {
+				t.Errorf("Filter(%s) includes %q", MsgCategoryNames[tt.cat], m.text)
+			}
+		}
+	}
+}
+
+func TestSearchMessages(t *testing.T) {
+	var msgs []Message
+	for _, m := range []string{"You hit the bat.", "The bat bites you.", "You miss the BAT.", "Welcome!"} {
+		msgs = append(msgs, Message{text: m})
+	}
+	tests := []struct {
+		text   string
+		before int
+		want   int
+	}{
+		{"bat", 4, 2},
+		{"bat", 2, 1},
+		{"bat", 1, 0},
+		{"bat", 0, -1},
+		{"BITES", 4, 1},
+		{"dragon", 4, -1},
+		{"welcome", 100, 3},
+	}
+	for _, tt := range tests {
+		if got := searchMessages(msgs, tt.text, tt.before); got != tt.want {
+			t.Errorf("searchMessages(%q, %d) = %d, want %d", tt.text, tt.before, got, tt.want)
+		}
+	}
+}
+
+func TestMessageLogTurns(t *testing.T) {
+	log := &MessageLog{}
+	log.Add(MsgSystem, "first")
+	log.SetTurn(5)
+	log.Add(MsgSystem, "second")
+	if got := log.Last(2); got[0].turn != 0 || got[1].turn != 5 {
+		t.Errorf("turns = %d, %d, want 0, 5", got[0].turn, got[1].turn)
+	}
+}
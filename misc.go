@@ -2,7 +2,7 @@ package main
 
 import (
 	"fmt"
-	"math/rand"
+	"sort"
 	"strconv"
 	"strings"
 )
@@ -12,22 +12,76 @@ type Actor interface {
 	Pos() Coord
 	SetPos(Coord)
 	Rune() rune
+	Color() string // display style, see Display.Init
 	AdjustHP(amt int)
 	Attack(Actor, *MessageLog)
 	ArmorClass() int
 	IsConfused() bool
 	IsBlind() bool
+	Speed() int
+	Energy() int
+	AdjustEnergy(amt int)
+}
+
+// --- SPEED -------------------------------------------------------------
+// Every game turn each actor gains energy equal to its speed and can act
+// once it has accumulated ActionCost energy.  A hasted actor with double
+// speed will act twice per turn, a slowed one every other turn.
+const (
+	NormalSpeed = 12
+	ActionCost  = 12
+)
+
+func canAct(a Actor) bool {
+	return a.Energy() >= ActionCost
+}
+
+func adjustSpeed(speed int, hasted, slowed bool) int {
+	if hasted {
+		speed *= 2
+	}
+	if slowed {
+		speed /= 2
+	}
+	return speed
 }
 
 // --- COMBAT ------------------------------------------------------------
 func attackHits(toHit int, targetAC int) bool {
-	roll := rand.Intn(20) + 1
+	roll := rng.Intn(20) + 1
 	target := toHit - targetAC
 	isHit := roll >= target
 	//debug.Add("hit? roll=%d target=%d (%d-%d)  -> %v", roll, target, toHit, targetAC, isHit)
 	return isHit
 }
 
+// --- SAVING THROWS -----------------------------------------------------
+type SaveType int
+
+// Higher values are harder to save against
+const (
+	VsPoison   SaveType = 0
+	VsParalyze SaveType = 0
+	VsDeath    SaveType = 0
+	VsBreath   SaveType = 2
+	VsMagic    SaveType = 3
+)
+
+// Returns the minimum d20 roll needed to make the saving throw
+func saveTarget(which SaveType, level int, bonus int) int {
+	return 14 + int(which) - level/2 - bonus
+}
+
+func savingThrow(which SaveType, level int, bonus int) bool {
+	roll := rng.Intn(20) + 1
+	return roll >= saveTarget(which, level, bonus)
+}
+
+// Equipment (e.g. rings of protection) that modifies the wearer's saves
+type SaveModifier interface {
+	SaveBonus() int
+}
+
 // -----------------------------------------------------------------------
 type Dice struct {
 	num, size, bonus int
@@ -82,7 +136,7 @@ func (d Dice) Roll() int {
 	sum := d.bonus
 	rolls := make([]int, d.num)
 	for i := 0; i < d.num; i++ {
-		roll := rand.Intn(d.size) + 1
+		roll := rng.Intn(d.size) + 1
 		rolls[i] = roll
 		sum += roll
 	}
@@ -132,6 +186,38 @@ func getDirectionCoords(dir Direction) Coord {
 
 // -----------------------------------------------------------------------
 
+// Returns the appropriate indefinite article ("a" or "an") for the given word
+func article(word string) string {
+	if word != "" && strings.ContainsRune("aeiou", rune(word[0])) {
+		return "an"
+	}
+	return "a"
+}
+
+// Returns a bar of the given width filled in proportion to amt out of total
+func progressBar(amt, total, width int) string {
+	filled := min(max(amt*width/max(total, 1), 0), width)
+	return "[" + strings.Repeat("#", filled) + strings.Repeat("-", width-filled) + "]"
+}
+
+// Returns the names in one of the item libraries in sorted order, so picking
+// one at random only depends on the seed
+func libNames[T any](lib map[string]T) []string {
+	names := make([]string, 0, len(lib))
+	for name := range lib {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func onOff(val bool) string {
+	if val {
+		return "on"
+	}
+	return "off"
+}
+
 func abs(val int) int {
 	if val < 0 {
 		val = -val
@@ -2,7 +2,6 @@ package main
 
 import (
 	"fmt"
-	"math/rand"
 	"strings"
 )
 
@@ -21,7 +20,9 @@ type MonsterTemplate struct {
 	Name        string
 	isMean      bool
 	noWander    bool
-	randMove    int // chance that it will move randomly (percentage)
+	randMove    int    // chance that it will move randomly (percentage)
+	speed       int    // energy gained per turn (see NormalSpeed)
+	color       string // display style, see Display.Init
 }
 
 // Index is used as difficulty of the monsters
@@ -32,32 +33,32 @@ type MonsterTemplate struct {
 // (apparently called "vorpalness" in original Rogue source code)
 // https://datadrivengamer.blogspot.com/2019/05/identifying-mechanics-of-rogue.html
 var MonsterLib = []MonsterTemplate{
-	{'K', 0, 2, 1, 7, "1d4", "swings at", "kobold", true, false, 0},
-	{'J', 0, 2, 1, 7, "1d2", "bites", "jackal", true, false, 0},
-	{'B', 0, 1, 1, 3, "1d2", "bites", "bat", false, false, 50}, // 50% chance to move randomly
-	{'S', 0, 3, 1, 5, "1d3", "bites", "snake", true, false, 0},
-	{'H', 0, 3, 1, 5, "1d8", "swings at", "hobgoblin", true, false, 0},
-	{'E', 0, 5, 1, 9, "0d0", "gazes at", "floating eye", false, true, 0}, // paralyzes 2-3 turns
-	{'A', 0, 10, 2, 3, "1d6", "stings", "giant ant", true, false, 0},     // decrease str
-	{'O', 15, 5, 1, 6, "1d7", "attacks", "orc", true, false, 0},
-	{'Z', 0, 7, 2, 8, "1d8", "slams", "zombie", true, false, 0},
-	{'G', 10, 8, 1, 5, "1d6", "attacks", "gnome", false, false, 0},
-	{'L', 0, 10, 3, 8, "1d1", "pickpockets", "leprechaun", false, true, 0}, // steal gold unless save vs magic
-	{'C', 15, 15, 4, 4, "1d6/1d6", "kicks/kicks", "centaur", false, false, 0},
-	{'R', 0, 25, 5, 2, "0d0/0d0", "bites/bites", "rust monster", true, false, 0}, // -1 to armor being worn
-	{'Q', 30, 35, 3, 2, "1d2/1d2/1d4", "claws/claws/bites", "quasit", true, false, 0},
-	{'N', 100, 40, 3, 9, "0d0", "pickpockets", "nymph", false, true, 0}, // steals random magic item from inventory
-	{'Y', 30, 50, 4, 6, "1d6/1d6", "swings/swings", "yeti", false, false, 0},
-	{'T', 50, 55, 6, 4, "1d8/1d8/2d6", "claws/claws/bites", "troll", true, true, 0},
-	{'W', 0, 55, 5, 4, "1d6", "touches", "wraith", true, false, 0},                // 15% chance to drain level and 1d10 max hp
-	{'F', 0, 85, 8, 3, "0d0", "sqeezes", "violet fungi", true, true, 0},           // grapple, damage is 1 then 2 then 3 etc.
-	{'I', 0, 120, 8, 3, "4d4", "swings at", "invisible stalker", true, false, 20}, // 20% chance to move randomly
-	{'X', 0, 120, 7, -2, "1d3/1d3/1d3/4d6", "claws/claws/claws/bites", "xorn", true, false, 0},
-	{'U', 40, 130, 8, 2, "3d4/3d4/2d5", "claws/claws/bites", "umber hulk", true, false, 0}, // confuses for 20-39 turns, only once
-	{'M', 30, 140, 7, 7, "3d4", "bites", "mimic", false, true, 0},
-	{'V', 30, 380, 8, 1, "1d10", "bites", "vampire", true, false, 0},
-	{'D', 100, 9000, 10, -1, "1d8/1d8/3d10", "claws/claws/bites", "dragon", false, true, 0},
-	{'P', 70, 7000, 15, 6, "2d12/2d4", "bites/stings", "purple worm", false, true, 0},
+	{'K', 0, 2, 1, 7, "1d4", "swings at", "kobold", true, false, 0, NormalSpeed, "green"},
+	{'J', 0, 2, 1, 7, "1d2", "bites", "jackal", true, false, 0, NormalSpeed, "brown"},
+	{'B', 0, 1, 1, 3, "1d2", "bites", "bat", false, false, 50, NormalSpeed * 3 / 2, "gray"}, // 50% chance to move randomly
+	{'S', 0, 3, 1, 5, "1d3", "bites", "snake", true, false, 0, NormalSpeed, "darkgreen"},
+	{'H', 0, 3, 1, 5, "1d8", "swings at", "hobgoblin", true, false, 0, NormalSpeed, "orange"},
+	{'E', 0, 5, 1, 9, "0d0", "gazes at", "floating eye", false, true, 0, NormalSpeed, "blue"}, // paralyzes 2-3 turns
+	{'A', 0, 10, 2, 3, "1d6", "stings", "giant ant", true, false, 0, NormalSpeed, "brown"},    // decrease str
+	{'O', 15, 5, 1, 6, "1d7", "attacks", "orc", true, false, 0, NormalSpeed, "yellow"},
+	{'Z', 0, 7, 2, 8, "1d8", "slams", "zombie", true, false, 0, NormalSpeed / 2, "gray"},
+	{'G', 10, 8, 1, 5, "1d6", "attacks", "gnome", false, false, 0, NormalSpeed, "brown"},
+	{'L', 0, 10, 3, 8, "1d1", "pickpockets", "leprechaun", false, true, 0, NormalSpeed, "green"}, // steal gold unless save vs magic
+	{'C', 15, 15, 4, 4, "1d6/1d6", "kicks/kicks", "centaur", false, false, 0, NormalSpeed, "yellow"},
+	{'R', 0, 25, 5, 2, "0d0/0d0", "bites/bites", "rust monster", true, false, 0, NormalSpeed, "orange"}, // -1 to armor being worn
+	{'Q', 30, 35, 3, 2, "1d2/1d2/1d4", "claws/claws/bites", "quasit", true, false, 0, NormalSpeed, "red"},
+	{'N', 100, 40, 3, 9, "0d0", "pickpockets", "nymph", false, true, 0, NormalSpeed, "magenta"}, // steals random magic item from inventory
+	{'Y', 30, 50, 4, 6, "1d6/1d6", "swings/swings", "yeti", false, false, 0, NormalSpeed, "white"},
+	{'T', 50, 55, 6, 4, "1d8/1d8/2d6", "claws/claws/bites", "troll", true, true, 0, NormalSpeed, "darkgreen"},
+	{'W', 0, 55, 5, 4, "1d6", "touches", "wraith", true, false, 0, NormalSpeed, "gray"},                   // 15% chance to drain level and 1d10 max hp
+	{'F', 0, 85, 8, 3, "0d0", "sqeezes", "violet fungi", true, true, 0, NormalSpeed, "purple"},            // grapple, damage is 1 then 2 then 3 etc.
+	{'I', 0, 120, 8, 3, "4d4", "swings at", "invisible stalker", true, false, 20, NormalSpeed, "default"}, // 20% chance to move randomly
+	{'X', 0, 120, 7, -2, "1d3/1d3/1d3/4d6", "claws/claws/claws/bites", "xorn", true, false, 0, NormalSpeed, "gray"},
+	{'U', 40, 130, 8, 2, "3d4/3d4/2d5", "claws/claws/bites", "umber hulk", true, false, 0, NormalSpeed, "brown"}, // confuses for 20-39 turns, only once
+	{'M', 30, 140, 7, 7, "3d4", "bites", "mimic", false, true, 0, NormalSpeed, "default"},
+	{'V', 30, 380, 8, 1, "1d10", "bites", "vampire", true, false, 0, NormalSpeed, "red"},
+	{'D', 100, 9000, 10, -1, "1d8/1d8/3d10", "claws/claws/bites", "dragon", false, true, 0, NormalSpeed, "red"},
+	{'P', 70, 7000, 15, 6, "2d12/2d4", "bites/stings", "purple worm", false, true, 0, NormalSpeed, "purple"},
 }
 
 // Uses public variable MonsterLib
@@ -73,7 +74,7 @@ func randomMonster(depth int) *Monster {
 
 	idx := len(MonsterLib) - 1 // Default to most difficult monster
 	if min < len(MonsterLib) { // Ensure we don't go out of bounds
-		idx = rand.Intn(max-min) + min
+		idx = rng.Intn(max-min) + min
 	}
 	//debug.Add("monster: len=%d, min=%d, max=%d, idx=%d", len(MonsterLib), min, max, idx)
 	return newMonster(idx)
@@ -119,6 +120,7 @@ type Monster struct {
 	Name        string
 	Level       int
 	HP          int
+	maxHP       int
 	AC          int
 	Attacks     []Dice
 	AttackVerbs []string
@@ -129,6 +131,11 @@ type Monster struct {
 	noWander    bool
 	randMove    int
 	nextStep    Coord
+	speed       int
+	energy      int
+	status      StatusEffects
+	vanished    bool // removed from the level without being defeated
+	color       string
 }
 
 const (
@@ -141,10 +148,12 @@ const (
 func newMonster(id int) *Monster {
 	mt := MonsterLib[id]
 
+	hp := mt.Level * (rng.Intn(8) + 1)
 	m := &Monster{
 		Name:        mt.Name,
 		Level:       mt.Level,
-		HP:          mt.Level * (rand.Intn(8) + 1),
+		HP:          hp,
+		maxHP:       hp,
 		AC:          mt.AC,
 		Attacks:     parseDice(mt.Attacks),
 		AttackVerbs: strings.Split(mt.AttackVerbs, "/"),
@@ -153,13 +162,16 @@ func newMonster(id int) *Monster {
 		isMean:      mt.isMean,
 		noWander:    mt.noWander,
 		randMove:    mt.randMove,
+		speed:       mt.speed,
+		color:       mt.color,
+		status:      newStatusEffects(),
 	}
 	return m
 }
 
 func (m *Monster) DebugString() string {
 	return fmt.Sprintf(
-		"%c (%2d,%2d) hp=%-2d ac=%-2d thac0=%-2d s=%d step=%v",
+		"%c (%2d,%2d) hp=%-2d ac=%-2d thac0=%-2d s=%d step=%v t=%v",
 		m.Symbol,
 		m.X, m.Y,
 		m.HP,
@@ -167,6 +179,7 @@ func (m *Monster) DebugString() string {
 		m.ToHit(),
 		m.State,
 		m.nextStep,
+		m.status,
 	)
 }
 
@@ -191,6 +204,23 @@ func (m Monster) String() string {
 	return m.Name
 }
 
+// A rough description of how hurt the monster is
+func (m *Monster) HealthString() string {
+	pct := m.HP * 100 / max(m.maxHP, 1)
+	switch {
+	case pct >= 100:
+		return "unhurt"
+	case pct > 66:
+		return "lightly wounded"
+	case pct > 33:
+		return "wounded"
+	case pct > 10:
+		return "badly wounded"
+	default:
+		return "almost dead"
+	}
+}
+
 // ----------------------------------------------------------------------
 // Implement the Actor interface
 
@@ -207,6 +237,10 @@ func (m *Monster) Rune() rune {
 	return m.Symbol
 }
 
+func (m *Monster) Color() string {
+	return m.color
+}
+
 func (m *Monster) AdjustHP(amt int) {
 	m.HP += amt
 }
@@ -224,26 +258,136 @@ func (m *Monster) Attack(a Actor, msg *MessageLog) {
 	for i, atk := range m.Attacks {
 		if attackHits(m.ToHit(), a.ArmorClass()) {
 			dmg := atk.Roll()
-			a.AdjustHP(-dmg)
-			msg.Add("%v %s you for %d damage.", label, m.AttackVerbs[i], dmg)
+			if dmg > 0 {
+				a.AdjustHP(-dmg)
+				msg.Add(MsgCombat, "%v %s you for %d damage.", label, m.AttackVerbs[i], dmg)
+			}
+			if p, ok := a.(*Player); ok {
+				m.SpecialAttack(p, label, msg)
+			}
+		} else {
+			msg.Add(MsgCombat, "%v misses you.", label)
+		}
+		if m.vanished {
+			break
+		}
+	}
+}
+
+// Additional effects of a monster's successful attack, most of which can be
+// avoided with a saving throw.
+func (m *Monster) SpecialAttack(p *Player, label string, msg *MessageLog) {
+	switch m.Symbol {
+
+	case 'E': // floating eye
+		if p.Save(VsParalyze) {
+			msg.Add(MsgCombat, "%v gazes at you, but you look away in time.", label)
 		} else {
-			msg.Add("%v misses you.", label)
+			msg.Add(MsgDanger, "You are transfixed by the gaze of the %v!", m)
+			p.ApplyStatus(StatusParalyzed, rng.Intn(2)+2, msg)
 		}
+
+	case 'A': // giant ant
+		if p.Save(VsPoison) {
+			msg.Add(MsgCombat, "A sting has momentarily weakened you.")
+		} else {
+			msg.Add(MsgDanger, "You feel a sting in your arm and now feel weaker.")
+			p.Str--
+		}
+
+	case 'L': // leprechaun
+		if p.Gold == 0 || p.Save(VsMagic) {
+			return
+		}
+		stolen := rng.Intn(50+10*p.depth) + 10
+		if stolen > p.Gold {
+			stolen = p.Gold
+		}
+		p.Gold -= stolen
+		m.vanished = true
+		msg.Add(MsgDanger, "Your purse feels lighter.")
+
+	case 'N': // nymph
+		var choices []int
+		for i, item := range p.inventory {
+			if !p.IsEquipped(item) {
+				choices = append(choices, i)
+			}
+		}
+		if len(choices) == 0 || p.Save(VsMagic) {
+			return
+		}
+		item := p.RemoveItem(choices[rng.Intn(len(choices))], 1)
+		m.vanished = true
+		msg.Add(MsgDanger, "She stole %v!", item.GndString())
 	}
 }
 
+func (m *Monster) Save(which SaveType) bool {
+	return savingThrow(which, m.Level, 0)
+}
+
 func (m *Monster) ArmorClass() int {
 	return m.AC
 }
 
 func (m *Monster) IsConfused() bool {
-	//TODO implement monster confusion
-	return false
+	return m.status.Has(StatusConfused)
 }
 
 func (m *Monster) IsBlind() bool {
-	//TODO implement monster blindness
-	return false
+	return m.status.Has(StatusBlind)
+}
+
+func (m *Monster) Speed() int {
+	return adjustSpeed(m.speed, m.IsHasted(), m.IsSlowed())
+}
+
+func (m *Monster) Energy() int {
+	return m.energy
+}
+
+func (m *Monster) AdjustEnergy(amt int) {
+	m.energy += amt
+}
+
+// ----------------------------------------------------------------------
+
+func (m *Monster) IsAsleep() bool {
+	return m.status.Has(StatusAsleep)
+}
+
+func (m *Monster) IsSlowed() bool {
+	return m.status.Has(StatusSlow)
+}
+
+func (m *Monster) IsHasted() bool {
+	return m.status.Has(StatusHaste)
+}
+
+// Wake up the monster if it was sleeping and have it start chasing the player.
+func (m *Monster) Disturb(msg *MessageLog) {
+	m.CureStatus(StatusAsleep, msg)
+	m.State = StateChase
+}
+
+// ----------------------------------------------------------------------
+
+func (m *Monster) Status(t StatusType) int {
+	return m.status.Turns(t)
+}
+
+func (m *Monster) ApplyStatus(t StatusType, turns int, msg *MessageLog) {
+	m.status.Apply(m, t, turns, msg)
+}
+
+func (m *Monster) CureStatus(t StatusType, msg *MessageLog) {
+	m.status.Cure(m, t, msg)
+}
+
+// Count down any status effects, called once per turn
+func (m *Monster) Update(msg *MessageLog) {
+	m.status.Tick(m, msg)
 }
 
 // ----------------------------------------------------------------------
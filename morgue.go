@@ -0,0 +1,170 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+/*************************************************************************
+ * Morgue
+ *
+ * A plain text dump of the character written when the game ends, so runs
+ * can be shared and compared.
+ */
+
+const MorgueMessages = 50 // number of messages included
+
+// -----------------------------------------------------------------------
+// Writes the dump to a new file in the directory (or the working directory if
+// none is given), returning its path
+func writeMorgue(gs *GameState, dir string) (string, error) {
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	path := filepath.Join(dir, fmt.Sprintf("%s-%s.txt", morgueName(gs.player.Name), now.Format("20060102-150405")))
+
+	if err := os.WriteFile(path, []byte(morgueText(gs, now)), 0o644); err != nil {
+		return "", err
+	}
+	return path, nil
+}
+
+// Turns the character's name into something safe to use in a file name, any
+// character other than a letter, digit, '-' or '_' becomes '_'
+func morgueName(name string) string {
+	return strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_') {
+			return unicode.ToLower(r)
+		}
+		return '_'
+	}, name)
+}
+
+// -----------------------------------------------------------------------
+func morgueText(gs *GameState, now time.Time) string {
+	p := gs.player
+	var b strings.Builder
+	section := func(title string) {
+		fmt.Fprintf(&b, "\n%s\n%s\n", title, strings.Repeat("-", len(title)))
+	}
+
+	fmt.Fprintf(&b, "GoRogue character dump, %s\n\n", now.Format("2006-01-02 15:04"))
+	fmt.Fprintf(&b, "%s was killed by %s on depth %d after %d turns.\n", p.Name, p.killedBy, p.depth, p.moves)
+	fmt.Fprintf(&b, "Score: %d\n", p.Score())
+	fmt.Fprintf(&b, "Seed:  %d\n", gs.seed)
+
+	section("Stats")
+	for _, str := range p.StatsStrings()[2:] { // skip the name
+		fmt.Fprintln(&b, str)
+	}
+	fmt.Fprintln(&b, "\nArmor Class")
+	for _, str := range p.ArmorStrings() {
+		fmt.Fprintf(&b, "  %s\n", str)
+	}
+
+	section("Inventory")
+	if len(p.inventory) == 0 {
+		fmt.Fprintln(&b, "empty")
+	}
+	for _, item := range p.inventory {
+		equip := ""
+		if p.IsEquipped(item) {
+			equip = " (equipped)"
+		}
+		fmt.Fprintf(&b, "%c) %v%s\n", p.Letter(item), item.InvString(), equip)
+	}
+
+	section("Effects")
+	if len(p.status.Active()) == 0 {
+		fmt.Fprintln(&b, "none")
+	}
+	for _, t := range p.status.Active() {
+		fmt.Fprintf(&b, "%s (%d turns)\n", StatusLib[t].name, p.status.Turns(t))
+	}
+
+	section("Kills")
+	fmt.Fprint(&b, killsText(gs.kills))
+
+	section(fmt.Sprintf("Map of depth %d", p.depth))
+	fmt.Fprint(&b, morgueMap(gs))
+
+	section("Last messages")
+	for _, m := range gs.messages.Last(MorgueMessages) {
+		fmt.Fprintf(&b, "%5d  %v\n", m.turn, m)
+	}
+	return b.String()
+}
+
+// Lists the monsters defeated, most often first
+func killsText(kills map[string]int) string {
+	names := make([]string, 0, len(kills))
+	total := 0
+	for name, n := range kills {
+		names = append(names, name)
+		total += n
+	}
+	sort.Slice(names, func(i, j int) bool {
+		if kills[names[i]] != kills[names[j]] {
+			return kills[names[i]] > kills[names[j]]
+		}
+		return names[i] < names[j]
+	})
+
+	if total == 0 {
+		return "none\n"
+	}
+	s := ""
+	for _, name := range names {
+		s += fmt.Sprintf("%4d %s\n", kills[name], name)
+	}
+	return s + fmt.Sprintf("%4d total\n", total)
+}
+
+// Draws the level with everything revealed onto an off-screen display and
+// returns it as text
+func morgueMap(gs *GameState) string {
+	sim := tcell.NewSimulationScreen("")
+	if err := sim.Init(); err != nil {
+		return err.Error() + "\n"
+	}
+	defer sim.Fini()
+	sim.SetSize(ScreenWidth, ScreenHeight)
+
+	d := Display{Screen: newViewScreen(sim)}
+	d.initStyles()
+	d.DrawMap(gs.dungeon, true)
+	for pos, item := range gs.items {
+		d.DrawItem(pos, item)
+	}
+	for _, m := range *gs.monsters {
+		d.DrawActor(m)
+	}
+	d.DrawPlayer(gs.player)
+	d.Show()
+
+	cells, width, _ := sim.GetContents()
+	lines := make([]string, MapMaxY)
+	for y := range lines {
+		row := make([]rune, width)
+		for x := range row {
+			row[x] = ' '
+			// y+1 to skip the message line
+			if c := cells[(y+1)*width+x]; len(c.Runes) > 0 {
+				row[x] = c.Runes[0]
+			}
+		}
+		lines[y] = strings.TrimRight(string(row), " ")
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
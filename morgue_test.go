@@ -0,0 +1,159 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMorgueName(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+	}{
+		{"Rodney", "rodney"},
+		{"Sir Rodney", "sir_rodney"},
+		{"rod-ney_2", "rod-ney_2"},
+		{"../../etc/passwd", "______etc_passwd"},
+		{`C:\rodney`, "c__rodney"},
+		{"Ródney", "r_dney"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := morgueName(tt.name); got != tt.want {
+			t.Errorf("morgueName(%q) = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestKillsText(t *testing.T) {
+	tests := []struct {
+		name  string
+		kills map[string]int
+		want  string
+	}{
+		{"none", nil, "none\n"},
+		{"one", map[string]int{"bat": 1}, "   1 bat\n   1 total\n"},
+		{"most first", map[string]int{"bat": 1, "kobold": 3, "hobgoblin": 2},
+			"   3 kobold\n   2 hobgoblin\n   1 bat\n   6 total\n"},
+		{"ties by name", map[string]int{"snake": 2, "bat": 2, "emu": 5},
+			"   5 emu\n   2 bat\n   2 snake\n   9 total\n"},
+	}
+	for _, tt := range tests {
+		if got := killsText(tt.kills); got != tt.want {
+			t.Errorf("%s: killsText() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// A small level with an item, a monster and the player, none of it seen yet
+func newMorgueState() *GameState {
+	gs := newTestState()
+	gs.dungeon = newTestMap([]string{
+		"------  ",
+		"|....+##",
+		"------  ",
+	}, 0)
+	gs.player.Name = "Rodney"
+	gs.player.SetPos(Coord{1, 1})
+	gs.items[Coord{3, 1}] = &Food{name: "apple", qty: 1}
+	gs.monsters.Add(&Monster{Name: "bat", Symbol: 'B'}, Coord{7, 1})
+	gs.kills = map[string]int{"kobold": 2}
+	return gs
+}
+
+func TestMorgueMap(t *testing.T) {
+	gs := newMorgueState()
+	lines := strings.Split(morgueMap(gs), "\n")
+
+	want := []string{
+		"------",
+		"|@.%.+#B",
+		"------",
+	}
+	if len(lines) != MapMaxY+1 {
+		t.Fatalf("morgueMap() has %d lines, want %d", len(lines), MapMaxY+1)
+	}
+	for y, w := range want {
+		if lines[y] != w {
+			t.Errorf("morgueMap() line %d = %q, want %q", y, lines[y], w)
+		}
+	}
+	for y, line := range lines[len(want):] {
+		if line != "" {
+			t.Errorf("morgueMap() line %d = %q, want it blank", len(want)+y, line)
+		}
+	}
+}
+
+func TestMorgueText(t *testing.T) {
+	gs := newMorgueState()
+	for i := 1; i <= MorgueMessages+10; i++ {
+		gs.messages.Add(MsgSystem, "Message %d.", i)
+	}
+	text := morgueText(gs, time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC))
+
+	for _, want := range []string{
+		"GoRogue character dump, 2024-05-01 12:30\n",
+		"\nStats\n-----\n",
+		"\nArmor Class\n",
+		"\nInventory\n---------\n",
+		"\nEffects\n-------\nnone\n",
+		"\nKills\n-----\n   2 kobold\n   2 total\n",
+		"\nMap of depth 0\n--------------\n------\n|@.%.+#B\n",
+		"\nLast messages\n-------------\n",
+	} {
+		if !strings.Contains(text, want) {
+			t.Errorf("morgueText() is missing %q", want)
+		}
+	}
+
+	// Only the last MorgueMessages messages are included
+	_, last, _ := strings.Cut(text, "Last messages")
+	for i := 1; i <= MorgueMessages+10; i++ {
+		msg := fmt.Sprintf("Message %d.", i)
+		if got, want := strings.Contains(last, msg), i > 10; got != want {
+			t.Errorf("morgueText() includes %q = %v, want %v", msg, got, want)
+		}
+	}
+}
+
+func TestWriteMorgue(t *testing.T) {
+	gs := newMorgueState()
+
+	dir := filepath.Join(t.TempDir(), "morgue")
+	path, err := writeMorgue(gs, dir)
+	if err != nil {
+		t.Fatalf("writeMorgue() error = %v", err)
+	}
+	if filepath.Dir(path) != dir || !strings.HasPrefix(filepath.Base(path), "rodney-") {
+		t.Errorf("writeMorgue() path = %q, want rodney-*.txt in %q", path, dir)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("writeMorgue() didn't write the file: %v", err)
+	}
+
+	// Without a directory the dump goes in the working directory
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	defer os.Chdir(wd)
+
+	path, err = writeMorgue(gs, "")
+	if err != nil {
+		t.Fatalf("writeMorgue(\"\") error = %v", err)
+	}
+	if filepath.Dir(path) != "." {
+		t.Errorf("writeMorgue(\"\") path = %q, want it in the working directory", path)
+	}
+	if _, err := os.Stat(path); err != nil {
+		t.Errorf("writeMorgue(\"\") didn't write the file: %v", err)
+	}
+}
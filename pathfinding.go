@@ -43,7 +43,9 @@ func (p Path) String() string {
 // more optimal but the complexity is low for this game (small map, only
 // a few monsters chasing at any given time.)
 // https://www.redblobgames.com/pathfinding/a-star/introduction.html
-func findPathBFS(dm *DungeonMap, start, end Coord) Path {
+// If knownOnly is set, only tiles the player has visited are considered.
+// The path will be empty if the end can't be reached.
+func findPathBFS(dm *DungeonMap, start, end Coord, knownOnly bool) Path {
 	// Declarations
 	frontier := CoordQueue{}
 	cameFrom := map[Coord]Coord{}
@@ -61,6 +63,9 @@ func findPathBFS(dm *DungeonMap, start, end Coord) Path {
 		nb := dm.getWalkableNeighbours(current)
 		for _, next := range nb {
 			_, reached := cameFrom[next]
+			if knownOnly && !dm.TileAt(next).visited {
+				continue
+			}
 			if !reached {
 				frontier.Add(next)
 				cameFrom[next] = current
@@ -77,6 +82,9 @@ func findPathBFS(dm *DungeonMap, start, end Coord) Path {
 		algo: "bfs",
 		iter: pathCount,
 	}
+	if !foundPath {
+		return path
+	}
 	var ok bool
 	current := end
 	for current != start {
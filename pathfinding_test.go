@@ -0,0 +1,62 @@
+package main
+
+import "testing"
+
+// Builds a map from rows of text drawn with TileRunes.  Tiles in columns
+// before knownTo have been visited by the player.
+func newTestMap(rows []string, knownTo int) *DungeonMap {
+	tiles := map[rune]TileType{
+		'.': TileFloor,
+		'#': TileCorridor,
+		'+': TileDoor,
+		'|': TileWallV,
+		'-': TileWallH,
+	}
+	dm := &DungeonMap{}
+	for y, row := range rows {
+		for x, r := range row {
+			dm.SetTile(Coord{x, y}, tiles[r])
+			dm.tiles[x][y].visited = x < knownTo
+		}
+	}
+	return dm
+}
+
+func TestFindPathBFS(t *testing.T) {
+	rows := []string{
+		"-------- -----",
+		"|......| |...|",
+		"|......+#+...|",
+		"|......| |...|",
+		"-------- -----",
+		"-----         ",
+		"|...|         ",
+		"-----         ",
+	}
+	tests := []struct {
+		name       string
+		start, end Coord
+		knownOnly  bool
+		knownTo    int
+		steps      int // 0 if there is no path
+	}{
+		{"same room", Coord{1, 1}, Coord{6, 3}, false, 0, 5},
+		{"through corridor", Coord{1, 2}, Coord{12, 2}, false, 0, 11},
+		{"sealed room", Coord{1, 1}, Coord{2, 6}, false, 0, 0},
+		{"into a wall", Coord{1, 1}, Coord{0, 1}, false, 0, 0},
+		{"already there", Coord{1, 1}, Coord{1, 1}, false, 0, 0},
+		{"known route", Coord{1, 2}, Coord{12, 2}, true, 14, 11},
+		{"unknown route", Coord{1, 2}, Coord{12, 2}, true, 8, 0},
+	}
+	for _, tt := range tests {
+		dm := newTestMap(rows, tt.knownTo)
+		path := findPathBFS(dm, tt.start, tt.end, tt.knownOnly)
+		if len(path.steps) != tt.steps {
+			t.Errorf("%s: path has %d steps, want %d", tt.name, len(path.steps), tt.steps)
+			continue
+		}
+		if tt.steps > 0 && path.steps[len(path.steps)-1] != tt.end {
+			t.Errorf("%s: path ends at %v, want %v", tt.name, path.steps[len(path.steps)-1], tt.end)
+		}
+	}
+}
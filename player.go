@@ -1,9 +1,6 @@
 package main
 
-import (
-	"fmt"
-	"math/rand"
-)
+import "fmt"
 
 var XPTable = [21]int{
 	0,
@@ -29,8 +26,19 @@ var XPTable = [21]int{
 	8000000,
 }
 
+// The equipment slots in the order they are listed, with their labels
+var EquipSlots = []string{"weapon", "armor", "left", "right"}
+
+var EquipSlotLabels = map[string]string{
+	"weapon": "W",
+	"armor":  "A",
+	"left":   "L",
+	"right":  "R",
+}
+
 // -----------------------------------------------------------------------
 type Player struct {
+	Name      string
 	X, Y      int
 	Symbol    rune
 	moves     int
@@ -46,23 +54,28 @@ type Player struct {
 	Gold      int
 	healCount int
 	foodCount int
+	energy    int
 	inventory []Item
+	letters   map[Item]rune
 	equiped   map[string]Equipable
-	timer     map[string]int
+	status    StatusEffects
 	killedBy  string
 }
 
 // -----------------------------------------------------------------------
 func (p *Player) Init() {
 	p.Str = 16
+	p.Name = "Nameless Hero"
 	p.maxStr = 16
 	p.HP = 12
 	p.maxHP = 12
 	p.AC = 10
 	p.Level = 1
 	p.foodCount = NutritionTime
-	p.timer = make(map[string]int)
-	p.equiped = map[string]Equipable{
+	p.energy = ActionCost
+	p.status = newStatusEffects()
+	p.letters = make(map[Item]rune)
+	p.equiped = map[string]Equipable{ // see EquipSlots
 		"weapon": nil,
 		"armor":  nil,
 		"left":   nil,
@@ -87,6 +100,10 @@ func (p *Player) Rune() rune {
 	return p.Symbol
 }
 
+func (p *Player) Color() string {
+	return "player"
+}
+
 func (p *Player) AdjustHP(amt int) {
 	p.HP += amt
 	if p.HP > p.maxHP {
@@ -107,9 +124,9 @@ func (p *Player) Attack(m Actor, msg *MessageLog) {
 		dmg := p.RollDamage()
 		m.AdjustHP(-dmg)
 		p.healCount++ // this shouldn't decrement when fighting
-		msg.Add("You hit %v for %d damage.", label, dmg)
+		msg.Add(MsgCombat, "You hit %v for %d damage.", label, dmg)
 	} else {
-		msg.Add("You miss %v.", label)
+		msg.Add(MsgCombat, "You miss %v.", label)
 	}
 }
 
@@ -117,22 +134,86 @@ func (p *Player) ArmorClass() int {
 	return p.AC
 }
 
+// The armor class as far as the player knows, leaving out the enchantment of
+// armor that hasn't been identified yet
+func (p *Player) KnownArmorClass() int {
+	if a, ok := p.equiped["armor"].(*Armor); ok && !a.known {
+		return a.AC
+	}
+	return p.AC
+}
+
 func (p *Player) IsConfused() bool {
-	return p.timer["confused"] > 0
+	return p.status.Has(StatusConfused)
 }
 
 func (p *Player) IsBlind() bool {
-	return p.timer["blind"] > 0
+	return p.status.Has(StatusBlind)
+}
+
+func (p *Player) Speed() int {
+	return adjustSpeed(NormalSpeed, p.IsHasted(), p.IsSlowed())
+}
+
+func (p *Player) Energy() int {
+	return p.energy
+}
+
+func (p *Player) AdjustEnergy(amt int) {
+	p.energy += amt
 }
 
 // -----------------------------------------------------------------------
 
 func (p *Player) IsParalyzed() bool {
-	return p.timer["paralyzed"] > 0
+	return p.status.Has(StatusParalyzed)
 }
 
 func (p *Player) IsHasted() bool {
-	return p.timer["haste"] > 0
+	return p.status.Has(StatusHaste)
+}
+
+func (p *Player) IsSlowed() bool {
+	return p.status.Has(StatusSlow)
+}
+
+// -----------------------------------------------------------------------
+
+func (p *Player) Save(which SaveType) bool {
+	return savingThrow(which, p.Level, p.SaveBonus())
+}
+
+// Total bonus to saving throws from the player's equipment
+func (p *Player) SaveBonus() int {
+	bonus := 0
+	for _, eq := range p.equiped {
+		if sm, ok := eq.(SaveModifier); ok {
+			bonus += sm.SaveBonus()
+		}
+	}
+	return bonus
+}
+
+// Chance out of 100 of making the given saving throw
+func (p *Player) SaveChance(which SaveType) int {
+	need := saveTarget(which, p.Level, p.SaveBonus())
+	chance := (21 - need) * 5
+	if chance < 0 {
+		chance = 0
+	} else if chance > 100 {
+		chance = 100
+	}
+	return chance
+}
+
+// How many squares the player can throw the item, further with more
+// strength.  Missiles go twice as far when fired from their launcher.
+func (p *Player) ThrowRange(item Item) int {
+	dist := max(p.Str/2, 2)
+	if w, ok := item.(*Weapon); ok && w.FiredFrom(p.equiped["weapon"]) {
+		dist *= 2
+	}
+	return dist
 }
 
 // -----------------------------------------------------------------------
@@ -190,19 +271,85 @@ func (p *Player) DamageDice() Dice {
 
 // -----------------------------------------------------------------------
 
+// Returns false if there is no room in the pack for the item
 func (p *Player) Pickup(item Item) bool {
 	switch item.(type) {
 	case *Gold:
 		p.Gold += item.(*Gold).qty
 		return true
-	default:
-		p.inventory = append(p.inventory, item)
-		return true
+	case Stackable:
+		// Add to an existing stack of the same kind if there is one
+		stack := item.(Stackable)
+		for _, other := range p.inventory {
+			if s, ok := other.(Stackable); ok && s.StacksWith(stack) {
+				s.AdjustCount(stack.Count())
+				return true
+			}
+		}
+	}
+	return p.addToPack(item)
+}
+
+// Adds the item to a new slot, giving it the first available letter.  The
+// inventory is kept sorted by letter and an item keeps its letter for as long
+// as it is in the pack.
+func (p *Player) addToPack(item Item) bool {
+	if len(p.inventory) >= MaxPack {
+		return false
 	}
+
+	letter := 'a'
+	idx := 0
+	for _, other := range p.inventory {
+		if p.letters[other] != letter {
+			break
+		}
+		letter++
+		idx++
+	}
+
+	p.letters[item] = letter
+	p.inventory = append(p.inventory, nil)
+	copy(p.inventory[idx+1:], p.inventory[idx:])
+	p.inventory[idx] = item
+	return true
 }
 
-func (p *Player) RemoveItem(idx int) {
+// Returns the inventory letter of the given item
+func (p *Player) Letter(item Item) rune {
+	return p.letters[item]
+}
+
+// Returns the index in the inventory of the item with the given letter or -1
+// if there isn't one.
+func (p *Player) InvIndex(letter rune) int {
+	for i, item := range p.inventory {
+		if p.letters[item] == letter {
+			return i
+		}
+	}
+	return -1
+}
+
+func (p *Player) IsEquipped(item Item) bool {
+	for _, eq := range p.equiped {
+		if eq != nil && eq == item {
+			return true
+		}
+	}
+	return false
+}
+
+// Removes qty items from the given inventory slot and returns them.  If that
+// leaves nothing in the slot (or the item doesn't stack) the slot is removed.
+func (p *Player) RemoveItem(idx int, qty int) Item {
+	item := p.inventory[idx]
+	if s, ok := item.(Stackable); ok && qty < s.Count() {
+		return s.Split(qty)
+	}
 	p.inventory = append(p.inventory[:idx], p.inventory[idx+1:]...)
+	delete(p.letters, item)
+	return item
 }
 
 // -----------------------------------------------------------------------
@@ -223,7 +370,7 @@ func (p *Player) CheckLevel() string {
 	//debug.Add("level: xp=%d, ply=%d level=%d", p.XP, p.Level, level)
 	if p.Level < level {
 		// Level Up!
-		hp := rand.Intn(12) + 1
+		hp := rng.Intn(12) + 1
 		p.HP += hp
 		p.maxHP += hp
 		msg = fmt.Sprintf("Welcome to level %d! [%+d HP]", level, hp)
@@ -242,6 +389,9 @@ func (p *Player) ResetHealCount() {
 }
 
 func (p *Player) AdjustFoodCount(amt int) {
+	if p.foodCount < 0 {
+		p.foodCount = 0
+	}
 	p.foodCount += amt
 	if p.foodCount > NutritionTime {
 		p.foodCount = NutritionTime
@@ -251,11 +401,13 @@ func (p *Player) AdjustFoodCount(amt int) {
 // -----------------------------------------------------------------------
 func (p *Player) Update(msg *MessageLog) {
 
-	// Decrement and timers that are set
-	for k := range p.timer {
-		p.timer[k]--
-		if p.timer[k] < 0 {
-			delete(p.timer, k)
+	// Count down any status effects
+	p.status.Tick(p, msg)
+
+	// Equipment is identified after being used for a while
+	for _, eq := range p.equiped {
+		if eq != nil && eq.Practice() {
+			msg.Add(MsgItem, "You are now familiar with your %v.", eq)
 		}
 	}
 
@@ -264,11 +416,18 @@ func (p *Player) Update(msg *MessageLog) {
 	f1 := p.foodCount
 	p.foodCount--
 	if f1 > HungerLimit && p.foodCount <= HungerLimit {
-		msg.Add("You are starting to get hungry.")
+		msg.Add(MsgStatus, "You are starting to get hungry.")
 	}
 	if f1 > WeakLimit && p.foodCount <= WeakLimit {
-		msg.Add("You are starting to feel weak.")
-		//TODO: handle feinting from hunger
+		msg.Add(MsgDanger, "You are starting to feel weak.")
+	}
+	if p.foodCount <= StarveLimit {
+		msg.Add(MsgDanger, "You collapse from hunger.")
+		p.HP = 0
+		p.killedBy = "starvation"
+	} else if p.foodCount <= FaintLimit && !p.IsParalyzed() && rng.Intn(5) == 0 {
+		msg.Add(MsgDanger, "You faint from the lack of food.")
+		p.ApplyStatus(StatusParalyzed, rng.Intn(8)+4, msg)
 	}
 
 	// Levels 1-7, heal one point every [21-LVL*2] turns without fighting.
@@ -279,7 +438,7 @@ func (p *Player) Update(msg *MessageLog) {
 		if p.Level < 8 {
 			p.AdjustHP(1)
 		} else {
-			amt := rand.Intn(p.Level - 7)
+			amt := rng.Intn(p.Level - 7)
 			p.AdjustHP(amt)
 		}
 		p.ResetHealCount()
@@ -290,16 +449,30 @@ func (p *Player) Update(msg *MessageLog) {
 
 // -----------------------------------------------------------------------
 
-func (p *Player) Timer(name string) int {
-	return p.timer[name]
+func (p *Player) Status(t StatusType) int {
+	return p.status.Turns(t)
 }
 
-func (p *Player) SetTimer(name string, val int) {
-	if val == 0 {
-		delete(p.timer, name)
-	} else {
-		p.timer[name] = val
+func (p *Player) ApplyStatus(t StatusType, turns int, msg *MessageLog) {
+	p.status.Apply(p, t, turns, msg)
+}
+
+func (p *Player) CureStatus(t StatusType, msg *MessageLog) {
+	p.status.Cure(p, t, msg)
+}
+
+// -----------------------------------------------------------------------
+// Returns how hungry the player is, or "" if not hungry at all
+func (p *Player) HungerString() string {
+	switch {
+	case p.foodCount <= FaintLimit:
+		return "Faint"
+	case p.foodCount <= WeakLimit:
+		return "Weak"
+	case p.foodCount <= HungerLimit:
+		return "Hungry"
 	}
+	return ""
 }
 
 // -----------------------------------------------------------------------
@@ -308,14 +481,16 @@ func (p *Player) InfoString() string {
 	switch {
 	case p.IsParalyzed():
 		condition = "Paralyzed"
-	case p.foodCount <= HungerLimit:
-		condition = "Hungry"
+	case p.HungerString() != "":
+		condition = p.HungerString()
 	case p.IsConfused():
 		condition = "Confused"
 	case p.IsBlind():
 		condition = "Blind"
 	case p.IsHasted():
 		condition = "Haste"
+	case p.IsSlowed():
+		condition = "Slow"
 	}
 
 	return fmt.Sprintf(
@@ -326,7 +501,7 @@ func (p *Player) InfoString() string {
 		p.maxHP,
 		p.Str,
 		p.ToHit(),
-		p.ArmorClass(),
+		p.KnownArmorClass(),
 		p.Level,
 		p.XP,
 		condition,
@@ -336,34 +511,49 @@ func (p *Player) InfoString() string {
 // -----------------------------------------------------------------------
 func (p *Player) StatsStrings() []string {
 
-	//savePoison := (7 + p.Level/2) * 5
-	//saveMagic := (4 + p.Level/2) * 5
-
-	savePoison := (7 + p.Level/2)
-	saveMagic := (4 + p.Level/2)
 	dice := p.DamageDice()
+	prev, next := XPTable[max(p.Level-1, 0)], XPTable[min(p.Level, len(XPTable)-1)]
+	hunger := p.HungerString()
+	if hunger == "" {
+		hunger = "Not hungry"
+	}
 
 	return []string{
-
-		fmt.Sprintf("Level:  %d", p.Level),
+		p.Name,
 		"",
-		fmt.Sprintf("Hit Points: %d / %d", p.HP, p.maxHP),
+		fmt.Sprintf("Level:      %d", p.Level),
+		fmt.Sprintf("Experience: %d / %d", p.XP, next),
+		fmt.Sprintf("            %s", progressBar(p.XP-prev, next-prev, 20)),
 		"",
-		fmt.Sprintf("Strength:   %d / %d", p.Str, p.maxStr),
-		//fmt.Sprintf("(%+d hit, %d dmg)", p.StrAttackBonus(), p.StrDamageBonus()),
-		fmt.Sprintf(" %+d hit", p.StrAttackBonus()),
-		fmt.Sprintf(" %+d dmg", p.StrDamageBonus()),
+		fmt.Sprintf("Hit Points: %d / %d", p.HP, p.maxHP),
+		fmt.Sprintf("Strength:   %d / %d  (%+d hit, %+d dmg)", p.Str, p.maxStr, p.StrAttackBonus(), p.StrDamageBonus()),
 		"",
-		fmt.Sprintf("THAC0:  %d    (%+d)", p.ToHit(), p.StrAttackBonus()),
-		fmt.Sprintf("Damage: %d-%-2d  (%+d)", dice.Min(), dice.Max(), p.StrDamageBonus()),
-		fmt.Sprintf("Armor:  %d", p.AC),
+		fmt.Sprintf("THAC0:      %d", p.ToHit()),
+		fmt.Sprintf("Damage:     %d-%d  (%s%+d)", dice.Min(), dice.Max(), p.Melee, p.StrDamageBonus()),
 		"",
-		fmt.Sprintf("Poison: %d", savePoison),
-		fmt.Sprintf("Magic:  %d", saveMagic),
+		"Saving throws",
+		fmt.Sprintf("  Poison, paralysis, death: %3d%%", p.SaveChance(VsPoison)),
+		fmt.Sprintf("  Breath:                   %3d%%", p.SaveChance(VsBreath)),
+		fmt.Sprintf("  Magic:                    %3d%%", p.SaveChance(VsMagic)),
 		"",
-		fmt.Sprintf("XP:     %d", p.XP),
-		fmt.Sprintf("Next:   %d", XPTable[p.Level]),
+		fmt.Sprintf("Hunger:     %s", hunger),
+		fmt.Sprintf("Depth:      %d", p.depth),
+		fmt.Sprintf("Gold:       %d", p.Gold),
+	}
+}
+
+// Lists where the player's armor class comes from
+func (p *Player) ArmorStrings() []string {
+	lines := []string{"Base:        10"}
+	if a, ok := p.equiped["armor"].(*Armor); ok {
+		lines = append(lines, fmt.Sprintf("%-12s %+d", a.Name+":", a.AC-10))
+		if !a.known {
+			// The total would give away the enchantment
+			return append(lines, "Enchantment: ?", "Total:       ?")
+		}
+		lines = append(lines, fmt.Sprintf("Enchantment: %+d", -a.ench))
 	}
+	return append(lines, fmt.Sprintf("Total:       %d", p.ArmorClass()))
 }
 
 func (p *Player) Score() int {
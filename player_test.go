@@ -0,0 +1,94 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestKnownArmorClass(t *testing.T) {
+	tests := []struct {
+		name  string
+		known bool
+		ench  int
+		want  int
+	}{
+		{"identified", true, 2, 5},
+		{"unknown", false, 2, 7},
+		{"unknown cursed", false, -1, 7},
+	}
+	for _, tt := range tests {
+		gs := newTestState()
+		a := newArmor("ring mail")
+		a.ench, a.known = tt.ench, tt.known
+		gs.player.Pickup(a)
+		a.Equip(gs.player, gs.messages)
+
+		if got := gs.player.KnownArmorClass(); got != tt.want {
+			t.Errorf("%s: KnownArmorClass() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+// A ring of protection, just enough to be worn
+type testRing struct{ bonus int }
+
+func (r *testRing) Rune() rune                        { return '=' }
+func (r *testRing) InvString() string                 { return "ring" }
+func (r *testRing) GndString() string                 { return "a ring" }
+func (r *testRing) Worth() int                        { return 0 }
+func (r *testRing) Equip(*Player, *MessageLog) bool   { return true }
+func (r *testRing) Unequip(*Player, *MessageLog) bool { return true }
+func (r *testRing) IsIdentified() bool                { return true }
+func (r *testRing) Identify()                         {}
+func (r *testRing) Practice() bool                    { return false }
+func (r *testRing) SaveBonus() int                    { return r.bonus }
+
+func TestSaveChance(t *testing.T) {
+	tests := []struct {
+		name  string
+		which SaveType
+		level int
+		rings []int
+		want  int
+	}{
+		{"level 1", VsPoison, 1, nil, 35},
+		{"level 10", VsPoison, 10, nil, 60},
+		{"vs magic", VsMagic, 1, nil, 20},
+		{"one ring", VsPoison, 1, []int{2}, 45},
+		{"two rings", VsPoison, 1, []int{2, 1}, 50},
+		{"cursed ring", VsMagic, 1, []int{-3}, 5},
+		{"can't fail", VsPoison, 20, []int{5, 5}, 100},
+	}
+	for _, tt := range tests {
+		gs := newTestState()
+		gs.player.Level = tt.level
+		for i, bonus := range tt.rings {
+			gs.player.equiped[[]string{"left", "right"}[i]] = &testRing{bonus}
+		}
+		if got := gs.player.SaveChance(tt.which); got != tt.want {
+			t.Errorf("%s: SaveChance() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestArmorStrings(t *testing.T) {
+	tests := []struct {
+		name  string
+		known bool
+		want  []string
+	}{
+		{"identified", true, []string{"Base:        10", "ring mail:   -3", "Enchantment: -2", "Total:       5"}},
+		{"unknown", false, []string{"Base:        10", "ring mail:   -3", "Enchantment: ?", "Total:       ?"}},
+	}
+	for _, tt := range tests {
+		gs := newTestState()
+		a := newArmor("ring mail")
+		a.ench, a.known = 2, tt.known
+		gs.player.Pickup(a)
+		a.Equip(gs.player, gs.messages)
+
+		if got := gs.player.ArmorStrings(); !slices.Equal(got, tt.want) {
+			t.Errorf("%s: ArmorStrings() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
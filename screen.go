@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// The classic layout: the message line, the map and the status line
+const ScreenWidth, ScreenHeight = MapMaxX, MapMaxY + 2
+const StatusRow = ScreenHeight - 1
+
+/*************************************************************************
+ * ViewScreen
+ *
+ * Wraps the tcell screen so everything is drawn relative to a view that is
+ * centred on the terminal.  What has been drawn is remembered so it can be
+ * laid out again whenever the terminal is resized, even in the middle of a
+ * prompt.  Mouse positions are translated to match.
+ */
+type ViewScreen struct {
+	tcell.Screen
+	cells    map[Coord]screenCell
+	cursor   Coord
+	showCurs bool
+	offX     int
+	offY     int
+	panel    bool // wide enough for the side panel
+	tooSmall bool
+}
+
+type screenCell struct {
+	r     rune
+	comb  []rune
+	style tcell.Style
+}
+
+func newViewScreen(scr tcell.Screen) *ViewScreen {
+	v := &ViewScreen{Screen: scr, cells: make(map[Coord]screenCell)}
+	v.layout()
+	return v
+}
+
+// -----------------------------------------------------------------------
+// Works out where the view goes for the current terminal size
+func (v *ViewScreen) layout() {
+	w, h := v.Screen.Size()
+	width := ScreenWidth
+	v.panel = w >= ScreenWidth+2+PanelWidth
+	if v.panel {
+		width += 2 + PanelWidth
+	}
+	v.tooSmall = w < ScreenWidth || h < ScreenHeight
+	v.offX = max((w-width)/2, 0)
+	v.offY = max((h-ScreenHeight)/2, 0)
+}
+
+// Lays out the view again and redraws everything in its new place
+func (v *ViewScreen) redraw() {
+	v.layout()
+	v.Screen.Clear()
+	for pos, c := range v.cells {
+		v.Screen.SetContent(pos.X+v.offX, pos.Y+v.offY, c.r, c.comb, c.style)
+	}
+	if v.showCurs {
+		v.ShowCursor(v.cursor.X, v.cursor.Y)
+	}
+	v.Show()
+}
+
+// -----------------------------------------------------------------------
+// Overrides of the tcell.Screen methods
+
+func (v *ViewScreen) Clear() {
+	clear(v.cells)
+	v.Screen.Clear()
+}
+
+func (v *ViewScreen) SetContent(x, y int, r rune, comb []rune, style tcell.Style) {
+	v.cells[Coord{x, y}] = screenCell{r, comb, style}
+	v.Screen.SetContent(x+v.offX, y+v.offY, r, comb, style)
+}
+
+func (v *ViewScreen) ShowCursor(x, y int) {
+	v.cursor, v.showCurs = Coord{x, y}, true
+	v.Screen.ShowCursor(x+v.offX, y+v.offY)
+}
+
+func (v *ViewScreen) HideCursor() {
+	v.showCurs = false
+	v.Screen.HideCursor()
+}
+
+func (v *ViewScreen) Show() {
+	if v.tooSmall {
+		w, h := v.Screen.Size()
+		v.Screen.Clear()
+		v.Screen.HideCursor()
+		lines := []string{
+			"Terminal too small",
+			fmt.Sprintf("need %dx%d, have %dx%d", ScreenWidth, ScreenHeight, w, h),
+		}
+		for i, line := range lines {
+			x, y := max((w-len(line))/2, 0), h/2-1+i
+			for j, r := range line {
+				v.Screen.SetContent(x+j, y, r, nil, tcell.StyleDefault)
+			}
+		}
+	}
+	v.Screen.Show()
+}
+
+// Resizes are handled here so every prompt survives them, mouse events are
+// translated into view positions.
+func (v *ViewScreen) PollEvent() tcell.Event {
+	ev := v.Screen.PollEvent()
+	switch ev := ev.(type) {
+	case *tcell.EventResize:
+		v.redraw()
+	case *tcell.EventMouse:
+		x, y := ev.Position()
+		return tcell.NewEventMouse(x-v.offX, y-v.offY, ev.Buttons(), ev.Modifiers())
+	}
+	return ev
+}
@@ -0,0 +1,35 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestViewScreenLayout(t *testing.T) {
+	tests := []struct {
+		w, h       int
+		tooSmall   bool
+		panel      bool
+		offX, offY int
+	}{
+		{80, 24, false, false, 0, 0},
+		{79, 24, true, false, 0, 0},
+		{80, 23, true, false, 0, 0},
+		{100, 30, false, false, 10, 3},
+		{112, 24, false, true, 0, 0},
+	}
+	for _, tt := range tests {
+		sim := tcell.NewSimulationScreen("")
+		if err := sim.Init(); err != nil {
+			t.Fatal(err)
+		}
+		sim.SetSize(tt.w, tt.h)
+		v := newViewScreen(sim)
+		if v.tooSmall != tt.tooSmall || v.panel != tt.panel || v.offX != tt.offX || v.offY != tt.offY {
+			t.Errorf("%dx%d: tooSmall %v, panel %v, offset %d,%d, want %v, %v, %d,%d", tt.w, tt.h,
+				v.tooSmall, v.panel, v.offX, v.offY, tt.tooSmall, tt.panel, tt.offX, tt.offY)
+		}
+		sim.Fini()
+	}
+}
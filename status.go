@@ -0,0 +1,209 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+)
+
+/*************************************************************************
+ * StatusLib
+ *
+ */
+type StatusType int
+
+const (
+	StatusBlind StatusType = iota
+	StatusConfused
+	StatusParalyzed
+	StatusAsleep
+	StatusHaste
+	StatusSlow
+	StatusDetMonsters
+	StatusDetMagic
+	StatusTruesight
+)
+
+// What happens when a status is applied to an actor that already has it
+type StackRule int
+
+const (
+	StackMax      StackRule = iota // keep whichever duration is longer
+	StackAdd                       // add the durations together
+	StackOverdose                  // the actor faints instead (e.g. haste)
+)
+
+type StatusTemplate struct {
+	name     string
+	stack    StackRule
+	onApply  func(s *StatusEffects, a Actor, msg *MessageLog)
+	onTick   func(s *StatusEffects, a Actor, msg *MessageLog) // before each turn is counted down
+	onExpire func(s *StatusEffects, a Actor, msg *MessageLog) // when it wears off or is cured
+}
+
+// An onExpire hook that lets the player know the status has worn off
+func tellPlayer(text string) func(s *StatusEffects, a Actor, msg *MessageLog) {
+	return func(s *StatusEffects, a Actor, msg *MessageLog) {
+		if isPlayer(a) {
+			msg.Add(MsgStatus, "%s", text)
+		}
+	}
+}
+
+var StatusLib = map[StatusType]StatusTemplate{
+	StatusBlind: {
+		name:     "blind",
+		stack:    StackAdd,
+		onExpire: tellPlayer("The veil of darkness lifts, you can see again."),
+	},
+	StatusConfused: {
+		name:     "confused",
+		stack:    StackAdd,
+		onExpire: tellPlayer("You feel less confused now."),
+	},
+	StatusParalyzed: {
+		name:     "paralyzed",
+		stack:    StackAdd,
+		onExpire: tellPlayer("You can move again."),
+	},
+	StatusAsleep: {
+		name:  "asleep",
+		stack: StackMax,
+	},
+	StatusHaste: {
+		name:     "haste",
+		stack:    StackOverdose,
+		onExpire: tellPlayer("You feel yourself slowing down."),
+		onApply: func(s *StatusEffects, a Actor, msg *MessageLog) {
+			delete(s.turns, StatusSlow) // haste and slow cancel each other out
+		},
+	},
+	StatusSlow: {
+		name:     "slow",
+		stack:    StackAdd,
+		onExpire: tellPlayer("You feel yourself speeding up."),
+		onApply: func(s *StatusEffects, a Actor, msg *MessageLog) {
+			delete(s.turns, StatusHaste)
+		},
+	},
+	StatusDetMonsters: {
+		name:  "monster detection",
+		stack: StackMax,
+	},
+	StatusDetMagic: {
+		name:  "detect magic",
+		stack: StackMax,
+	},
+	StatusTruesight: {
+		name:     "truesight",
+		stack:    StackMax,
+		onExpire: tellPlayer("Everything looks SO boring now."),
+		onApply: func(s *StatusEffects, a Actor, msg *MessageLog) {
+			delete(s.turns, StatusBlind)
+		},
+	},
+}
+
+/*************************************************************************
+ * StatusEffects
+ * the timed effects on a single actor, shared by Player and Monster
+ */
+type StatusEffects struct {
+	turns map[StatusType]int
+}
+
+func newStatusEffects() StatusEffects {
+	return StatusEffects{make(map[StatusType]int)}
+}
+
+func (s *StatusEffects) Has(t StatusType) bool {
+	return s.turns[t] > 0
+}
+
+// Returns the number of turns remaining for the given status
+func (s *StatusEffects) Turns(t StatusType) int {
+	return s.turns[t]
+}
+
+func (s *StatusEffects) Apply(a Actor, t StatusType, turns int, msg *MessageLog) {
+	templ := StatusLib[t]
+
+	if s.Has(t) {
+		switch templ.stack {
+		case StackMax:
+			s.turns[t] = max(s.turns[t], turns)
+		case StackAdd:
+			s.turns[t] += turns
+		case StackOverdose:
+			// Too much of a good thing, the actor faints for 0-7 turns
+			delete(s.turns, t)
+			if isPlayer(a) {
+				msg.Add(MsgDanger, "You faint from exhaustion.")
+			}
+			if faint := rng.Intn(8); faint > 0 {
+				s.Apply(a, StatusParalyzed, faint, msg)
+			}
+		}
+		return
+	}
+
+	s.turns[t] = turns
+	if templ.onApply != nil {
+		templ.onApply(s, a, msg)
+	}
+}
+
+// Removes the status right away (e.g. healing curing blindness)
+func (s *StatusEffects) Cure(a Actor, t StatusType, msg *MessageLog) {
+	if s.Has(t) {
+		s.expire(a, t, msg)
+	}
+}
+
+// Decrement all active statuses, called once per turn.  They are gone through
+// in a fixed order so the messages (and anything random the hooks do) don't
+// change from game to game.
+func (s *StatusEffects) Tick(a Actor, msg *MessageLog) {
+	for _, t := range s.Active() {
+		if templ := StatusLib[t]; templ.onTick != nil {
+			templ.onTick(s, a, msg)
+		}
+		if !s.Has(t) {
+			continue // cured by a hook
+		}
+		s.turns[t]--
+		if s.turns[t] <= 0 {
+			s.expire(a, t, msg)
+		}
+	}
+}
+
+func (s *StatusEffects) expire(a Actor, t StatusType, msg *MessageLog) {
+	delete(s.turns, t)
+	templ := StatusLib[t]
+	if templ.onExpire != nil {
+		templ.onExpire(s, a, msg)
+	}
+}
+
+// Returns the active statuses in a consistent order
+func (s *StatusEffects) Active() []StatusType {
+	var list []StatusType
+	for t := range s.turns {
+		list = append(list, t)
+	}
+	slices.Sort(list)
+	return list
+}
+
+func (s StatusEffects) String() string {
+	str := ""
+	for t, turns := range s.turns {
+		str += fmt.Sprintf("%s:%d ", StatusLib[t].name, turns)
+	}
+	return str
+}
+
+func isPlayer(a Actor) bool {
+	_, ok := a.(*Player)
+	return ok
+}
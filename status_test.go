@@ -0,0 +1,86 @@
+package main
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestStatusHooks(t *testing.T) {
+	const StatusTest StatusType = 100
+	var ticks, expires []int
+	StatusLib[StatusTest] = StatusTemplate{
+		name: "test",
+		onTick: func(s *StatusEffects, a Actor, msg *MessageLog) {
+			ticks = append(ticks, s.Turns(StatusTest))
+		},
+		onExpire: func(s *StatusEffects, a Actor, msg *MessageLog) {
+			expires = append(expires, s.Turns(StatusTest))
+		},
+	}
+	defer delete(StatusLib, StatusTest)
+
+	s := newStatusEffects()
+	msg := &MessageLog{}
+	m := &Monster{}
+	s.Apply(m, StatusTest, 3, msg)
+	for i := 0; i < 4; i++ {
+		s.Tick(m, msg)
+	}
+	if want := []int{3, 2, 1}; !slices.Equal(ticks, want) {
+		t.Errorf("onTick saw %v turns left, want %v", ticks, want)
+	}
+	if len(expires) != 1 {
+		t.Errorf("onExpire called %d times, want once", len(expires))
+	}
+	if s.Has(StatusTest) {
+		t.Error("status still active after expiring")
+	}
+}
+
+func TestStatusExpireMessage(t *testing.T) {
+	tests := []struct {
+		name  string
+		actor Actor
+		want  int // messages shown
+	}{
+		{"player", &Player{}, 1},
+		{"monster", &Monster{}, 0},
+	}
+	for _, tt := range tests {
+		s := newStatusEffects()
+		msg := &MessageLog{}
+		s.Apply(tt.actor, StatusConfused, 1, msg)
+		s.Tick(tt.actor, msg)
+		if got := len(msg.messages); got != tt.want {
+			t.Errorf("%s: %d messages when confusion wore off, want %d", tt.name, got, tt.want)
+		}
+		if s.Has(StatusConfused) {
+			t.Errorf("%s: still confused", tt.name)
+		}
+	}
+}
+
+// Statuses wearing off on the same turn always give their messages in the
+// same order
+func TestStatusTickOrder(t *testing.T) {
+	var first []string
+	for i := 0; i < 20; i++ {
+		s := newStatusEffects()
+		msg := &MessageLog{}
+		p := &Player{}
+		for _, st := range []StatusType{StatusTruesight, StatusSlow, StatusConfused, StatusBlind} {
+			s.turns[st] = 1
+		}
+		s.Tick(p, msg)
+
+		var got []string
+		for _, m := range msg.messages {
+			got = append(got, m.text)
+		}
+		if first == nil {
+			first = got
+		} else if !slices.Equal(got, first) {
+			t.Fatalf("messages in a different order:\n%q\n%q", first, got)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+/*************************************************************************
+ * Theme
+ *
+ * Overrides the colours of the named styles set up in Display.Init (other
+ * names are reported as errors), e.g.
+ *
+ *   {
+ *     "monochrome": false,
+ *     "styles": {
+ *       "red":        "#ff5555",
+ *       "remembered": "darkslategray",
+ *       "highlight":  "black/yellow"
+ *     }
+ *   }
+ *
+ * Colours are tcell colour names or #rrggbb, given as "fg" or "fg/bg".
+ */
+
+type ThemeFile struct {
+	Monochrome bool              `json:"monochrome"`
+	Styles     map[string]string `json:"styles"`
+}
+
+// -----------------------------------------------------------------------
+// Loads the theme file (if there is one) on top of the default palette.  Any
+// problems are returned rather than being fatal.
+func (d *Display) LoadTheme(path string) []error {
+	if path == "" {
+		return nil
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return []error{err}
+	}
+
+	var file ThemeFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return []error{fmt.Errorf("%s: %w", path, err)}
+	}
+	d.monochrome = file.Monochrome
+
+	// Sort the names so errors are reported consistently
+	names := make([]string, 0, len(file.Styles))
+	for name := range file.Styles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var errs []error
+	for _, name := range names {
+		if _, ok := d.styles[name]; !ok {
+			errs = append(errs, fmt.Errorf("unknown style %q", name))
+			continue
+		}
+		style, err := parseStyle(file.Styles[name])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", name, err))
+			continue
+		}
+		d.styles[name] = style
+	}
+	return errs
+}
+
+func parseStyle(str string) (tcell.Style, error) {
+	style := tcell.StyleDefault
+	fg, bg, hasBg := strings.Cut(str, "/")
+
+	c, err := parseColor(fg)
+	if err != nil {
+		return style, err
+	}
+	style = style.Foreground(c)
+
+	if hasBg {
+		if c, err = parseColor(bg); err != nil {
+			return style, err
+		}
+		style = style.Background(c)
+	}
+	return style, nil
+}
+
+func parseColor(name string) (tcell.Color, error) {
+	name = strings.TrimSpace(name)
+	c := tcell.GetColor(name)
+	if c == tcell.ColorDefault && !strings.EqualFold(name, "default") {
+		return c, fmt.Errorf("unknown colour %q", name)
+	}
+	return c, nil
+}
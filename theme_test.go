@@ -0,0 +1,105 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    tcell.Color
+		wantErr bool
+	}{
+		{"red", tcell.ColorRed, false},
+		{"darkslategray", tcell.ColorDarkSlateGray, false},
+		{" yellow ", tcell.ColorYellow, false},
+		{"#ff5555", tcell.NewRGBColor(0xff, 0x55, 0x55), false},
+		{"default", tcell.ColorDefault, false},
+		{"", tcell.ColorDefault, true},
+		{"reddish", tcell.ColorDefault, true},
+		{"#ff55", tcell.ColorDefault, true},
+	}
+	for _, tt := range tests {
+		got, err := parseColor(tt.name)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseColor(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseColor(%q) = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestParseStyle(t *testing.T) {
+	tests := []struct {
+		str     string
+		want    tcell.Style
+		wantErr bool
+	}{
+		{"red", tcell.StyleDefault.Foreground(tcell.ColorRed), false},
+		{"black/yellow", tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorYellow), false},
+		{"#ffffff/navy", tcell.StyleDefault.Foreground(tcell.NewRGBColor(0xff, 0xff, 0xff)).Background(tcell.ColorNavy), false},
+		{"white/", tcell.StyleDefault, true},
+		{"nope/yellow", tcell.StyleDefault, true},
+		{"black/nope", tcell.StyleDefault, true},
+	}
+	for _, tt := range tests {
+		got, err := parseStyle(tt.str)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseStyle(%q) error = %v, wantErr %v", tt.str, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && got != tt.want {
+			t.Errorf("parseStyle(%q) = %v, want %v", tt.str, got, tt.want)
+		}
+	}
+}
+
+func TestLoadTheme(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "theme.json")
+	data := `{
+		"monochrome": true,
+		"styles": {
+			"red":     "#ff5555",
+			"reddish": "red",
+			"blue":    "bluish"
+		}
+	}`
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var d Display
+	d.initStyles()
+	errs := d.LoadTheme(path)
+
+	want := []string{`blue: unknown colour "bluish"`, `unknown style "reddish"`}
+	if len(errs) != len(want) {
+		t.Fatalf("LoadTheme() errors = %v, want %v", errs, want)
+	}
+	for i, err := range errs {
+		if err.Error() != want[i] {
+			t.Errorf("LoadTheme() error %d = %q, want %q", i, err, want[i])
+		}
+	}
+	if _, ok := d.styles["reddish"]; ok {
+		t.Errorf("LoadTheme() added unknown style %q", "reddish")
+	}
+	if got := d.styles["red"]; got != tcell.StyleDefault.Foreground(tcell.NewRGBColor(0xff, 0x55, 0x55)) {
+		t.Errorf("red style = %v, want #ff5555", got)
+	}
+	if got := d.styles["blue"]; got != tcell.StyleDefault.Foreground(tcell.ColorBlue) {
+		t.Errorf("blue style = %v, want unchanged", got)
+	}
+	if !d.monochrome {
+		t.Errorf("monochrome = false, want true")
+	}
+	if errs := d.LoadTheme(filepath.Join(t.TempDir(), "missing.json")); errs != nil {
+		t.Errorf("LoadTheme(missing) = %v, want nil", errs)
+	}
+}